@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/assets"
+)
+
+// bearerPrefix is the scheme of the Authorization header the admin token is sent in
+const bearerPrefix = "Bearer "
+
+// aliasesMutex serialises the updates of the asset aliases, so the saved table is always the applied one
+var aliasesMutex sync.Mutex
+
+// RequireAdminToken returns the middleware rejecting the requests without the bearer token.
+// An empty token disables the admin API, its routes are not found
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			respondError(c, NewNotFoundError("the admin API is disabled", nil))
+			c.Abort()
+			return
+		}
+		authorization := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authorization, bearerPrefix) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, bearerPrefix)), []byte(token)) != 1 {
+			respondError(c, NewUnauthorizedError())
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func getAssetAliases(c *gin.Context) {
+	c.JSON(http.StatusOK, assets.Default.Table())
+}
+
+func putAssetAliases(c *gin.Context) {
+	var t assets.Table
+	if err := c.BindJSON(&t); err != nil {
+		glog.Errorf("putAssetAliases: cannot parse asset aliases due to error %s", err)
+		respondError(c, NewInvalidParameterError("body", "the body must be an asset aliases table in JSON format"))
+		return
+	}
+	if err := t.Validate(); err != nil {
+		respondError(c, NewInvalidParameterError("body", err.Error()))
+		return
+	}
+	aliasesMutex.Lock()
+	defer aliasesMutex.Unlock()
+	// The table is saved before it is applied, so a failed save leaves the current table in use
+	if current := assets.Default.Table(); t.Version <= current.Version {
+		glog.Errorf("putAssetAliases: cannot update asset aliases to version %d due to error %s", t.Version, assets.ErrStaleVersion)
+		respondError(c, NewConflictError(assets.ErrStaleVersion.Error(), gin.H{"version": current.Version}))
+		return
+	}
+	if *assetAliasesFile != "" {
+		if err := assets.SaveFile(*assetAliasesFile, &t); err != nil {
+			glog.Errorf("putAssetAliases: cannot save asset aliases version %d due to error %s", t.Version, err)
+			respondError(c, err)
+			return
+		}
+	}
+	if err := assets.Default.Update(&t); err != nil {
+		glog.Errorf("putAssetAliases: cannot update asset aliases to version %d due to error %s", t.Version, err)
+		respondError(c, NewConflictError(err.Error(), gin.H{"version": assets.Default.Table().Version}))
+		return
+	}
+	glog.Infof("putAssetAliases: asset aliases are updated to version %d", t.Version)
+	c.JSON(http.StatusOK, assets.Default.Table())
+}
+
+func getCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, snapshotsCache.Stats())
+}
+
+func getQuarantine(c *gin.Context) {
+	entries, err := quarantine.List()
+	if err != nil {
+		glog.Errorf("getQuarantine: cannot list the quarantined snapshots due to error %s", err)
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/assets"
+)
+
+func testAdminRouter(token string) *gin.Engine {
+	r := gin.New()
+	admin := r.Group("/admin", RequireAdminToken(token))
+	admin.GET("/assets/aliases", getAssetAliases)
+	admin.PUT("/assets/aliases", putAssetAliases)
+	return r
+}
+
+func adminRequest(r *gin.Engine, method, token, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/admin/assets/aliases", bytes.NewBufferString(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireAdminToken(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, adminRequest(testAdminRouter(""), http.MethodGet, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(testAdminRouter("secret"), http.MethodGet, "wrong", "").Code)
+	assert.Equal(t, http.StatusOK, adminRequest(testAdminRouter("secret"), http.MethodGet, "secret", "").Code)
+
+	// The token must be sent with the bearer scheme
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/admin/assets/aliases", nil)
+	req.Header.Set("Authorization", "secret")
+	testAdminRouter("secret").ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPutAssetAliasesSaveFailure(t *testing.T) {
+	defer func(r *assets.Registry) { assets.Default = r }(assets.Default)
+	assets.Default = assets.NewRegistry(assets.DefaultTable())
+	dir, err := ioutil.TempDir("", "aliases")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(path string) { *assetAliasesFile = path }(*assetAliasesFile)
+
+	r := testAdminRouter("secret")
+	*assetAliasesFile = filepath.Join(dir, "missing", "aliases.json")
+	w := adminRequest(r, http.MethodPut, "secret", `{"version": 2, "aliases": {"XBT": "BTC"}}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, 1, assets.Default.Table().Version)
+
+	*assetAliasesFile = filepath.Join(dir, "aliases.json")
+	w = adminRequest(r, http.MethodPut, "secret", `{"version": 2, "aliases": {"XBT": "BTC"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, assets.Default.Table().Version)
+	saved, err := assets.LoadFile(*assetAliasesFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, saved.Version)
+
+	w = adminRequest(r, http.MethodPut, "secret", `{"version": 2, "aliases": {"XBT": "BTC"}}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,185 @@
+// Package archive keeps the raw responses of the exchanges, so the snapshots can be regenerated from them
+// when the parsing is fixed
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Response type is the metadata of an archived raw response of an exchange. The compressed size is recorded
+// only by the fetch which stored the content
+type Response struct {
+	Exchange       string `json:"exchange"`
+	URL            string `json:"url"`
+	FetchedAt      int64  `json:"fetched_at"`
+	ContentHash    string `json:"content_hash"`
+	Size           int    `json:"size"`
+	CompressedSize int    `json:"compressed_size,omitempty"`
+}
+
+// Archive type stores the responses compressed and addressed by the hash of their content, so the identical
+// responses are stored once, and the metadata of every fetch next to them
+type Archive struct {
+	store Store
+}
+
+// NewArchive instantiates Archive object keeping the responses in the store
+func NewArchive(store Store) *Archive {
+	a := Archive{store: store}
+	return &a
+}
+
+// Default is the archive the fetchers store the raw responses in, nothing is archived if it is nil
+var Default *Archive
+
+// Record stores the raw response in the default archive if there is one. The failures are only logged
+// as the archive must not stop the fetches
+func Record(exchange, url string, fetchedAt time.Time, raw []byte) {
+	if Default == nil {
+		return
+	}
+	if _, err := Default.Put(exchange, url, fetchedAt, raw); err != nil {
+		glog.Errorf("Record: cannot archive the response of exchange '%s' due to error %s", exchange, err)
+	}
+}
+
+// objectKey returns the key of the compressed content with the hash
+func objectKey(hash string) string {
+	return fmt.Sprintf("objects/%s/%s.gz", hash[:2], hash)
+}
+
+// metadataPrefix returns the prefix of the keys of the metadata of the exchange
+func metadataPrefix(exchange string) string {
+	return fmt.Sprintf("responses/%s/", exchange)
+}
+
+// dayPrefix returns the prefix of the keys of the metadata of the responses of the exchange fetched on the day of t
+func dayPrefix(exchange string, t time.Time) string {
+	return fmt.Sprintf("%s%s/", metadataPrefix(exchange), t.UTC().Format("2006-01-02"))
+}
+
+// metadataKey returns the key of the metadata of the response. The keys of a day share a directory
+// and sort in the order of the fetches
+func metadataKey(r *Response) string {
+	t := time.Unix(0, r.FetchedAt*int64(time.Millisecond))
+	return fmt.Sprintf("%s%d-%s.json", dayPrefix(r.Exchange, t), r.FetchedAt, r.ContentHash[:12])
+}
+
+// Put archives the raw response of the exchange fetched from the url at fetchedAt
+func (a *Archive) Put(exchange, url string, fetchedAt time.Time, raw []byte) (*Response, error) {
+	sum := sha256.Sum256(raw)
+	r := Response{
+		Exchange:    exchange,
+		URL:         url,
+		FetchedAt:   fetchedAt.UnixNano() / int64(time.Millisecond),
+		ContentHash: hex.EncodeToString(sum[:]),
+		Size:        len(raw)}
+
+	key := objectKey(r.ContentHash)
+	exists, err := a.store.Exists(key)
+	if err != nil {
+		return nil, err
+	}
+	// The identical responses are stored once, so only the first of them is compressed
+	if !exists {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		if _, err := w.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		if err := a.store.Put(key, compressed.Bytes()); err != nil {
+			glog.Errorf("Archive.Put: cannot store the response of exchange '%s' due to error %s", exchange, err)
+			return nil, err
+		}
+		r.CompressedSize = compressed.Len()
+	}
+
+	metadata, err := json.Marshal(&r)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.store.Put(metadataKey(&r), metadata); err != nil {
+		glog.Errorf("Archive.Put: cannot store the metadata of the response of exchange '%s' due to error %s", exchange, err)
+		return nil, err
+	}
+	return &r, nil
+}
+
+// List returns the metadata of the responses of the exchange fetched between from and to inclusive in the order of the fetches.
+// Only the keys of the days between from and to are listed
+func (a *Archive) List(exchange string, from, to time.Time) ([]Response, error) {
+	keys := make([]string, 0)
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayKeys, err := a.store.List(dayPrefix(exchange, day))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, dayKeys...)
+	}
+	fromMs := from.UnixNano() / int64(time.Millisecond)
+	toMs := to.UnixNano() / int64(time.Millisecond)
+	r := make([]Response, 0)
+	for _, key := range keys {
+		name := path.Base(key)
+		fetchedAt, err := strconv.ParseInt(name[:strings.Index(name+"-", "-")], 10, 64)
+		if err != nil {
+			glog.Warningf("Archive.List: skipping unexpected key '%s'", key)
+			continue
+		}
+		if fetchedAt < fromMs || fetchedAt > toMs {
+			continue
+		}
+		data, err := a.store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		var response Response
+		if err := json.Unmarshal(data, &response); err != nil {
+			glog.Errorf("Archive.List: cannot parse the metadata '%s' due to error %s", key, err)
+			continue
+		}
+		r = append(r, response)
+	}
+	sort.SliceStable(r, func(i, j int) bool { return r[i].FetchedAt < r[j].FetchedAt })
+	return r, nil
+}
+
+// Load returns the raw content of the archived response checking it against its hash
+func (a *Archive) Load(r *Response) ([]byte, error) {
+	compressed, err := a.store.Get(objectKey(r.ContentHash))
+	if err != nil {
+		glog.Errorf("Archive.Load: cannot get the response with hash '%s' due to error %s", r.ContentHash, err)
+		return nil, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		glog.Errorf("Archive.Load: cannot decompress the response with hash '%s' due to error %s", r.ContentHash, err)
+		return nil, err
+	}
+	defer reader.Close()
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		glog.Errorf("Archive.Load: cannot decompress the response with hash '%s' due to error %s", r.ContentHash, err)
+		return nil, err
+	}
+	if sum := sha256.Sum256(raw); hex.EncodeToString(sum[:]) != r.ContentHash {
+		return nil, fmt.Errorf("Archive.Load: the content of the response does not match its hash '%s'", r.ContentHash)
+	}
+	return raw, nil
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testArchive(t *testing.T) (*Archive, Store, func()) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.NoError(t, err)
+	store, err := NewFileStore(dir)
+	assert.NoError(t, err)
+	return NewArchive(store), store, func() { os.RemoveAll(dir) }
+}
+
+func TestArchivePutLoad(t *testing.T) {
+	a, store, cleanup := testArchive(t)
+	defer cleanup()
+
+	fetchedAt := time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC)
+	raw := []byte(`{"symbols":[]}`)
+	r, err := a.Put("binance", "http://binance/exchangeInfo", fetchedAt, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1546336800000), r.FetchedAt)
+	assert.Equal(t, len(raw), r.Size)
+	assert.Len(t, r.ContentHash, 64)
+
+	assert.NotZero(t, r.CompressedSize)
+
+	// The identical response is stored once
+	second, err := a.Put("binance", "http://binance/exchangeInfo", fetchedAt.Add(time.Minute), raw)
+	assert.NoError(t, err)
+	assert.Zero(t, second.CompressedSize)
+	objects, err := store.List("objects/")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	responses, err := a.List("binance", fetchedAt, fetchedAt.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, responses, 2)
+	assert.Equal(t, *r, responses[0])
+
+	loaded, err := a.Load(&responses[1])
+	assert.NoError(t, err)
+	assert.Equal(t, raw, loaded)
+}
+
+func TestArchiveList(t *testing.T) {
+	a, _, cleanup := testArchive(t)
+	defer cleanup()
+
+	day := time.Date(2019, 1, 1, 23, 59, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := a.Put("bitfinex", "http://bitfinex/symbols_details", day.Add(time.Duration(i)*time.Minute), []byte{byte(i)})
+		assert.NoError(t, err)
+	}
+	responses, err := a.List("bitfinex", day.Add(time.Minute), day.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, responses, 2)
+	assert.True(t, responses[0].FetchedAt < responses[1].FetchedAt)
+
+	responses, err = a.List("binance", day, day.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, responses, 0)
+}
+
+// listingStore records the prefixes listed in the store
+type listingStore struct {
+	Store
+	prefixes []string
+}
+
+func (s *listingStore) List(prefix string) ([]string, error) {
+	s.prefixes = append(s.prefixes, prefix)
+	return s.Store.List(prefix)
+}
+
+func TestArchiveListDays(t *testing.T) {
+	_, store, cleanup := testArchive(t)
+	defer cleanup()
+	listing := &listingStore{Store: store}
+	a := NewArchive(listing)
+
+	day := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := a.Put("binance", "http://binance/exchangeInfo", day.AddDate(0, 0, i), []byte{byte(i)})
+		assert.NoError(t, err)
+	}
+	responses, err := a.List("binance", day.Add(time.Hour), day.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Len(t, responses, 1)
+	assert.Equal(t, []string{"responses/binance/2019-01-01/", "responses/binance/2019-01-02/"}, listing.prefixes)
+}
+
+func TestArchiveLoadCorrupted(t *testing.T) {
+	a, store, cleanup := testArchive(t)
+	defer cleanup()
+
+	r, err := a.Put("binance", "http://binance/exchangeInfo", time.Now(), []byte("response"))
+	assert.NoError(t, err)
+	other, err := a.Put("binance", "http://binance/exchangeInfo", time.Now(), []byte("other"))
+	assert.NoError(t, err)
+	data, err := store.Get(objectKey(other.ContentHash))
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(objectKey(r.ContentHash), data))
+
+	_, err = a.Load(r)
+	assert.Error(t, err)
+}
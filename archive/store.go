@@ -0,0 +1,128 @@
+package archive
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// ErrNotFound is returned when there is no object with the key in the store
+var ErrNotFound = errors.New("the object is not found in the store")
+
+// Store is the interface of the object stores the archived responses are kept in.
+// The keys are slash separated paths
+type Store interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Exists(key string) (bool, error)
+	// List returns the keys starting with the prefix in the lexical order
+	List(prefix string) ([]string, error)
+}
+
+// FileStore is an implementation of Store interface keeping every object in a file under a directory
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore instantiates object of Store interface (FileStore class). The directory is created if missing
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		glog.Errorf("NewFileStore: cannot create directory '%s' due to error %s", dir, err)
+		return nil, err
+	}
+	s := FileStore{dir: dir}
+	return &s, nil
+}
+
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Put writes the object into a temporary file renamed to the file of the key, so the readers never see a partial object
+func (s *FileStore) Put(key string, data []byte) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		glog.Errorf("FileStore.Put: cannot create directory of '%s' due to error %s", path, err)
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		glog.Errorf("FileStore.Put: cannot create temporary file for '%s' due to error %s", path, err)
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		glog.Errorf("FileStore.Put: cannot write file '%s' due to error %s", path, err)
+		return err
+	}
+	return nil
+}
+
+// Get reads the object of the key
+func (s *FileStore) Get(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		glog.Errorf("FileStore.Get: cannot read object '%s' due to error %s", key, err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// Exists returns true if there is an object with the key
+func (s *FileStore) Exists(key string) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		glog.Errorf("FileStore.Exists: cannot check object '%s' due to error %s", key, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// List walks the directory of the prefix and returns the keys of the files starting with the prefix
+func (s *FileStore) List(prefix string) ([]string, error) {
+	root := s.path(prefix[:strings.LastIndex(prefix, "/")+1])
+	keys := make([]string, 0)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		glog.Errorf("FileStore.List: cannot list objects with prefix '%s' due to error %s", prefix, err)
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,196 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// ErrStaleVersion is returned when the updated table does not have a greater version than the current one
+var ErrStaleVersion = errors.New("the version of the asset aliases table must be greater than the current one")
+
+// Table type is a versioned set of aliases mapping the native asset codes of the exchanges to the normalised ones.
+// The aliases of an exchange take precedence over the aliases applied to all the exchanges
+type Table struct {
+	Version   int                          `json:"version"`
+	Aliases   map[string]string            `json:"aliases"`
+	Exchanges map[string]map[string]string `json:"exchanges,omitempty"`
+}
+
+// DefaultTable returns the aliases used when no table file is given
+func DefaultTable() *Table {
+	t := Table{
+		Version: 1,
+		Aliases: map[string]string{
+			"XBT": "BTC"},
+		Exchanges: map[string]map[string]string{
+			"bitfinex": {
+				"UST": "USDT",
+				"IOT": "MIOTA",
+				"DSH": "DASH",
+				"QTM": "QTUM",
+				"MNA": "MANA",
+				"DAT": "DATA",
+				"QSH": "QASH",
+				"YYW": "YOYOW",
+				"AIO": "AION",
+				"SNG": "SNGLS"}}}
+	return &t
+}
+
+// Validate checks that the table has a positive version and no empty asset codes
+func (t *Table) Validate() error {
+	if t.Version <= 0 {
+		return fmt.Errorf("Validate: version %d is not a positive integer", t.Version)
+	}
+	if err := validateAliases(t.Aliases); err != nil {
+		return err
+	}
+	for exchange, aliases := range t.Exchanges {
+		if exchange == "" {
+			return errors.New("Validate: exchange name is empty")
+		}
+		if err := validateAliases(aliases); err != nil {
+			return fmt.Errorf("%s for exchange '%s'", err, exchange)
+		}
+	}
+	return nil
+}
+
+func validateAliases(aliases map[string]string) error {
+	for native, normalised := range aliases {
+		if strings.TrimSpace(native) == "" || strings.TrimSpace(normalised) == "" {
+			return fmt.Errorf("Validate: alias '%s' -> '%s' has an empty asset code", native, normalised)
+		}
+	}
+	return nil
+}
+
+// Copy returns a deep copy of the table
+func (t *Table) Copy() *Table {
+	c := Table{
+		Version:   t.Version,
+		Aliases:   copyAliases(t.Aliases),
+		Exchanges: make(map[string]map[string]string, len(t.Exchanges))}
+	for exchange, aliases := range t.Exchanges {
+		c.Exchanges[exchange] = copyAliases(aliases)
+	}
+	return &c
+}
+
+// copyAliases copies the aliases with the asset codes in upper case
+func copyAliases(aliases map[string]string) map[string]string {
+	c := make(map[string]string, len(aliases))
+	for native, normalised := range aliases {
+		c[strings.ToUpper(native)] = strings.ToUpper(normalised)
+	}
+	return c
+}
+
+// LoadFile reads the table from the JSON file
+func LoadFile(path string) (*Table, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		glog.Errorf("LoadFile: cannot read asset aliases file '%s' due to error %s", path, err)
+		return nil, err
+	}
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		glog.Errorf("LoadFile: cannot parse asset aliases file '%s' due to error %s", path, err)
+		return nil, err
+	}
+	if err := t.Validate(); err != nil {
+		glog.Errorf("LoadFile: invalid asset aliases file '%s' due to error %s", path, err)
+		return nil, err
+	}
+	return t.Copy(), nil
+}
+
+// SaveFile writes the table into the JSON file
+func SaveFile(path string, t *Table) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		glog.Errorf("SaveFile: cannot marshal asset aliases due to error %s", err)
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		glog.Errorf("SaveFile: cannot write asset aliases file '%s' due to error %s", path, err)
+		return err
+	}
+	return nil
+}
+
+// Registry type holds the current asset aliases table and normalises the asset codes with it
+type Registry struct {
+	mu    sync.RWMutex
+	table *Table
+}
+
+// Default is the registry used by the fetchers
+var Default = NewRegistry(DefaultTable())
+
+// NewRegistry creates a registry with the table
+func NewRegistry(t *Table) *Registry {
+	r := Registry{table: t.Copy()}
+	return &r
+}
+
+// Table returns a copy of the current table
+func (r *Registry) Table() *Table {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.table.Copy()
+}
+
+// Update replaces the current table with a valid table of a greater version
+func (r *Registry) Update(t *Table) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.Version <= r.table.Version {
+		return ErrStaleVersion
+	}
+	r.table = t.Copy()
+	return nil
+}
+
+// Normalise returns the normalised code of the native asset code of the exchange
+func (r *Registry) Normalise(exchange string, asset string) string {
+	asset = strings.ToUpper(asset)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if normalised, ok := r.table.Exchanges[exchange][asset]; ok {
+		return normalised
+	}
+	if normalised, ok := r.table.Aliases[asset]; ok {
+		return normalised
+	}
+	return asset
+}
+
+// NormaliseSymbols replaces the base and quote assets of the symbols of the exchange with the normalised codes
+// keeping the native codes in NativeBaseAsset and NativeQuoteAsset
+func (r *Registry) NormaliseSymbols(exchange string, symbols []types.SymbolInfo) {
+	for i := range symbols {
+		s := &symbols[i]
+		if s.NativeBaseAsset == "" {
+			s.NativeBaseAsset = s.BaseAsset
+		}
+		if s.NativeQuoteAsset == "" {
+			s.NativeQuoteAsset = s.QuoteAsset
+		}
+		s.BaseAsset = r.Normalise(exchange, s.NativeBaseAsset)
+		if s.NativeQuoteAsset != "" {
+			s.QuoteAsset = r.Normalise(exchange, s.NativeQuoteAsset)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package assets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestNormalise(t *testing.T) {
+	r := NewRegistry(DefaultTable())
+	assert.Equal(t, "BTC", r.Normalise("bitfinex", "XBT"))
+	assert.Equal(t, "BTC", r.Normalise("binance", "xbt"))
+	assert.Equal(t, "USDT", r.Normalise("bitfinex", "UST"))
+	assert.Equal(t, "UST", r.Normalise("binance", "UST"))
+	assert.Equal(t, "ETH", r.Normalise("binance", "ETH"))
+}
+
+func TestNormaliseSymbols(t *testing.T) {
+	r := NewRegistry(DefaultTable())
+	symbols := []types.SymbolInfo{
+		{Symbol: "tIOTUST", BaseAsset: "IOT", QuoteAsset: "UST"},
+		{Symbol: "fUST", BaseAsset: "UST"}}
+	r.NormaliseSymbols("bitfinex", symbols)
+
+	assert.Equal(t, "MIOTA", symbols[0].BaseAsset)
+	assert.Equal(t, "USDT", symbols[0].QuoteAsset)
+	assert.Equal(t, "IOT", symbols[0].NativeBaseAsset)
+	assert.Equal(t, "UST", symbols[0].NativeQuoteAsset)
+	assert.Equal(t, "USDT", symbols[1].BaseAsset)
+	assert.Equal(t, "", symbols[1].QuoteAsset)
+	assert.Equal(t, "", symbols[1].NativeQuoteAsset)
+
+	// Normalising again starts from the native codes
+	r.NormaliseSymbols("binance", symbols)
+	assert.Equal(t, "IOT", symbols[0].BaseAsset)
+	assert.Equal(t, "UST", symbols[0].QuoteAsset)
+}
+
+func TestUpdate(t *testing.T) {
+	r := NewRegistry(DefaultTable())
+	assert.Equal(t, ErrStaleVersion, r.Update(&Table{Version: 1, Aliases: map[string]string{"XBT": "BTC"}}))
+	assert.Error(t, r.Update(&Table{Version: 2, Aliases: map[string]string{"XBT": ""}}))
+
+	assert.NoError(t, r.Update(&Table{Version: 2, Aliases: map[string]string{"xdg": "doge"}}))
+	assert.Equal(t, 2, r.Table().Version)
+	assert.Equal(t, "DOGE", r.Normalise("binance", "XDG"))
+	assert.Equal(t, "XBT", r.Normalise("binance", "XBT"))
+}
+
+func TestTableIsCopied(t *testing.T) {
+	r := NewRegistry(DefaultTable())
+	table := r.Table()
+	table.Aliases["XBT"] = "XBT"
+	assert.Equal(t, "BTC", r.Normalise("binance", "XBT"))
+}
+
+func TestSaveAndLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "assets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "aliases.json")
+	assert.NoError(t, SaveFile(path, DefaultTable()))
+	loaded, err := LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTable(), loaded)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"version": 0}`), 0644))
+	_, err = LoadFile(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/etrubenok/make-trades-registry/fetchers"
+	"github.com/etrubenok/make-trades-registry/formats"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+const (
+	// cacheControlImmutable is sent with the snapshots which never change, e.g. the ones taken long enough ago
+	cacheControlImmutable = "public, max-age=31536000, immutable"
+	// cacheControlRecent is sent with the snapshots of the recent past which a fetch in progress may still add to,
+	// they are cached for one fetch period
+	cacheControlRecent = "public, max-age=60"
+	// cacheControlLatest makes the clients revalidate the latest snapshots on every request
+	cacheControlLatest = "public, no-cache"
+	// freshnessAgeBucket is the age in seconds during which the latest snapshots keep the same ETag
+	freshnessAgeBucket = 60
+)
+
+// immutableAfter is the time after which no more snapshots taken before it are saved. The time of a snapshot is
+// the start of its fetch, so it covers the fetch period and the margin for the slow fetches and saves
+const immutableAfter = fetchers.FetchPeriod + 5*time.Minute
+
+// CacheValidators type contains the validators of a response with snapshots and its Cache-Control header
+type CacheValidators struct {
+	ETag         string
+	LastModified time.Time
+	CacheControl string
+}
+
+// NewCacheValidators creates the validators of the response with the snapshots in the format.
+// The ETag is derived from the times and the content hashes of the snapshots and, for the snapshots annotated with the freshness,
+// from the stale flags and the age rounded down to freshnessAgeBucket. Such ETag is weak as the exact age in the body
+// changes within the bucket. Last-Modified is the time of the latest of the snapshots
+func NewCacheValidators(f formats.Format, r *types.APIExchangesSymbols, cacheControl string) *CacheValidators {
+	h := sha256.New()
+	h.Write([]byte(f))
+	var lastModified int64
+	weak := false
+	for _, e := range r.Exchanges {
+		h.Write([]byte("|" + e.Exchange + ":" + strconv.FormatInt(e.SnapshotTime, 10) + ":" + e.ContentHash))
+		// The clients revalidating the latest snapshots learn when they become stale and get the age updated
+		if e.Freshness != nil {
+			weak = true
+			h.Write([]byte(":" + strconv.FormatInt(int64(e.Freshness.AgeSeconds)/freshnessAgeBucket, 10)))
+			if e.Freshness.Stale {
+				h.Write([]byte(":stale"))
+			}
+		}
+		if e.SnapshotTime > lastModified {
+			lastModified = e.SnapshotTime
+		}
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+	v := CacheValidators{
+		ETag:         etag,
+		LastModified: time.Unix(0, lastModified*int64(time.Millisecond)).UTC(),
+		CacheControl: cacheControl}
+	return &v
+}
+
+// CheckNotModified sets the caching headers and responds with 304 Not Modified if the client has the current version.
+// If-None-Match takes precedence over If-Modified-Since. It returns true if the response is written
+func (v *CacheValidators) CheckNotModified(c *gin.Context) bool {
+	c.Header("ETag", v.ETag)
+	c.Header("Last-Modified", v.LastModified.Format(http.TimeFormat))
+	c.Header("Vary", "Accept")
+	c.Header("Cache-Control", v.CacheControl)
+
+	notModified := false
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		notModified = matchETag(ifNoneMatch, v.ETag)
+	} else if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" && !strings.HasPrefix(v.ETag, "W/") {
+		// The time of the snapshots does not change with their age, so the annotated ones are validated by the ETag only
+		if t, err := http.ParseTime(ifModifiedSince); err == nil {
+			notModified = !v.LastModified.Truncate(time.Second).After(t)
+		}
+	}
+	if notModified {
+		c.AbortWithStatus(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// matchETag returns true if the If-None-Match header lists the ETag. The weak comparison is used as required for If-None-Match
+func matchETag(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Immutable returns true if the snapshots requested by the query cannot change anymore: the requested time
+// or the end of the requested day is more than immutableAfter in the past
+func (q *SnapshotQuery) Immutable(now time.Time) bool {
+	if q.Latest {
+		return false
+	}
+	if q.At != nil {
+		return q.At.Add(immutableAfter).Before(now)
+	}
+	nextDay := time.Date(q.Year, time.Month(q.Month), q.Day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	return !now.Before(nextDay.Add(immutableAfter))
+}
+
+// CacheControl returns the Cache-Control header of the response to the query
+func (q *SnapshotQuery) CacheControl(now time.Time) string {
+	switch {
+	case q.Latest:
+		return cacheControlLatest
+	case q.Immutable(now):
+		return cacheControlImmutable
+	default:
+		return cacheControlRecent
+	}
+}
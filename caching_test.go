@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/formats"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testSnapshots(contentHash string) *types.APIExchangesSymbols {
+	return &types.APIExchangesSymbols{
+		Exchanges: []types.APIExchangeSymbols{
+			{Exchange: "binance", SnapshotTime: 1546300800500, ContentHash: contentHash},
+			{Exchange: "bitfinex", SnapshotTime: 1546300700000, ContentHash: "b"}}}
+}
+
+func TestNewCacheValidators(t *testing.T) {
+	v := NewCacheValidators(formats.JSON, testSnapshots("a"), cacheControlLatest)
+	assert.Equal(t, time.Date(2019, 1, 1, 0, 0, 0, 500000000, time.UTC), v.LastModified)
+	assert.Equal(t, v.ETag, NewCacheValidators(formats.JSON, testSnapshots("a"), cacheControlLatest).ETag)
+	assert.NotEqual(t, v.ETag, NewCacheValidators(formats.JSON, testSnapshots("c"), cacheControlLatest).ETag)
+	assert.NotEqual(t, v.ETag, NewCacheValidators(formats.CSV, testSnapshots("a"), cacheControlLatest).ETag)
+
+	// The body has the time of the snapshot, the same content taken later is another version
+	later := testSnapshots("a")
+	later.Exchanges[0].SnapshotTime += 60000
+	assert.NotEqual(t, v.ETag, NewCacheValidators(formats.JSON, later, cacheControlLatest).ETag)
+
+	stale := testSnapshots("a")
+	stale.Exchanges[0].Freshness = &types.APISnapshotFreshness{Stale: true}
+	assert.NotEqual(t, v.ETag, NewCacheValidators(formats.JSON, stale, cacheControlLatest).ETag)
+}
+
+func TestNewCacheValidatorsFreshness(t *testing.T) {
+	annotated := func(age float64) *types.APIExchangesSymbols {
+		r := testSnapshots("a")
+		r.Exchanges[0].Freshness = &types.APISnapshotFreshness{AgeSeconds: age, MaxAgeSeconds: 300}
+		return r
+	}
+	assert.False(t, strings.HasPrefix(NewCacheValidators(formats.JSON, testSnapshots("a"), cacheControlLatest).ETag, "W/"))
+
+	v := NewCacheValidators(formats.JSON, annotated(5), cacheControlLatest)
+	assert.True(t, strings.HasPrefix(v.ETag, "W/"))
+	assert.Equal(t, v.ETag, NewCacheValidators(formats.JSON, annotated(59.5), cacheControlLatest).ETag)
+	assert.NotEqual(t, v.ETag, NewCacheValidators(formats.JSON, annotated(60), cacheControlLatest).ETag)
+
+	c, w := testContext("/symbols")
+	c.Request.Header.Set("If-None-Match", v.ETag)
+	assert.True(t, v.CheckNotModified(c))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// The time of the snapshots does not tell whether the age in the body is current
+	c, _ = testContext("/symbols")
+	c.Request.Header.Set("If-Modified-Since", "Tue, 01 Jan 2019 00:00:00 GMT")
+	assert.False(t, v.CheckNotModified(c))
+}
+
+func TestCheckNotModified(t *testing.T) {
+	v := NewCacheValidators(formats.JSON, testSnapshots("a"), cacheControlLatest)
+
+	c, w := testContext("/symbols")
+	assert.False(t, v.CheckNotModified(c))
+	assert.Equal(t, v.ETag, w.Header().Get("ETag"))
+	assert.Equal(t, "Tue, 01 Jan 2019 00:00:00 GMT", w.Header().Get("Last-Modified"))
+	assert.Equal(t, cacheControlLatest, w.Header().Get("Cache-Control"))
+
+	c, w = testContext("/symbols")
+	c.Request.Header.Set("If-None-Match", `"other", `+v.ETag)
+	assert.True(t, v.CheckNotModified(c))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	c, _ = testContext("/symbols")
+	c.Request.Header.Set("If-None-Match", `"other"`)
+	c.Request.Header.Set("If-Modified-Since", "Tue, 01 Jan 2019 00:00:00 GMT")
+	assert.False(t, v.CheckNotModified(c))
+
+	c, _ = testContext("/symbols")
+	c.Request.Header.Set("If-Modified-Since", "Tue, 01 Jan 2019 00:00:00 GMT")
+	assert.True(t, v.CheckNotModified(c))
+
+	c, _ = testContext("/symbols")
+	c.Request.Header.Set("If-Modified-Since", "Mon, 31 Dec 2018 23:59:59 GMT")
+	assert.False(t, v.CheckNotModified(c))
+
+	c, w = testContext("/symbols")
+	assert.False(t, NewCacheValidators(formats.JSON, testSnapshots("a"), cacheControlImmutable).CheckNotModified(c))
+	assert.Equal(t, cacheControlImmutable, w.Header().Get("Cache-Control"))
+}
+
+func TestSnapshotQueryImmutable(t *testing.T) {
+	now := time.Date(2019, 1, 2, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	assert.True(t, (&SnapshotQuery{At: &past}).Immutable(now))
+	assert.False(t, (&SnapshotQuery{At: &future}).Immutable(now))
+	assert.True(t, (&SnapshotQuery{Year: 2019, Month: 1, Day: 1}).Immutable(now))
+	assert.False(t, (&SnapshotQuery{Year: 2019, Month: 1, Day: 2}).Immutable(now))
+	assert.False(t, (&SnapshotQuery{Year: 2019, Month: 1, Day: 1, Latest: true}).Immutable(now))
+
+	// The snapshots taken before the recent times may still be saved
+	recent := now.Add(-time.Minute)
+	assert.False(t, (&SnapshotQuery{At: &recent}).Immutable(now))
+	assert.False(t, (&SnapshotQuery{Year: 2019, Month: 1, Day: 1}).Immutable(time.Date(2019, 1, 2, 0, 1, 0, 0, time.UTC)))
+}
+
+func TestSnapshotQueryCacheControl(t *testing.T) {
+	now := time.Date(2019, 1, 2, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	recent := now.Add(-time.Minute)
+	assert.Equal(t, cacheControlLatest, (&SnapshotQuery{Year: 2019, Month: 1, Day: 2, Latest: true}).CacheControl(now))
+	assert.Equal(t, cacheControlImmutable, (&SnapshotQuery{At: &past}).CacheControl(now))
+	assert.Equal(t, cacheControlRecent, (&SnapshotQuery{At: &recent}).CacheControl(now))
+	assert.Equal(t, cacheControlRecent, (&SnapshotQuery{Year: 2019, Month: 1, Day: 2}).CacheControl(now))
+}
@@ -0,0 +1,123 @@
+package changes
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Diff compares two snapshots of symbols of the same exchange and returns the added, removed and modified symbols.
+// The before snapshot can be nil, in that case all the symbols of the after snapshot are reported as added
+func Diff(before, after *types.ExchangeSymbols) *types.SymbolsDiff {
+	d := types.SymbolsDiff{
+		ExchangeID:     after.ExchangeID,
+		ToSnapshotTime: after.SnapshotTime,
+		Added:          make([]types.SymbolInfo, 0),
+		Removed:        make([]types.SymbolInfo, 0),
+		Modified:       make([]types.SymbolModification, 0)}
+
+	beforeSymbols := make(map[string]types.SymbolInfo)
+	if before != nil {
+		d.FromSnapshotTime = before.SnapshotTime
+		for _, s := range before.Symbols {
+			beforeSymbols[s.Symbol] = s
+		}
+	}
+	afterSymbols := make(map[string]bool)
+	for _, s := range after.Symbols {
+		afterSymbols[s.Symbol] = true
+		b, ok := beforeSymbols[s.Symbol]
+		if !ok {
+			d.Added = append(d.Added, s)
+			continue
+		}
+		fields := CompareSymbolInfo(&b, &s)
+		if len(fields) > 0 {
+			d.Modified = append(d.Modified, types.SymbolModification{
+				Symbol: s.Symbol,
+				Before: b,
+				After:  s,
+				Fields: fields})
+		}
+	}
+	if before != nil {
+		for _, s := range before.Symbols {
+			if !afterSymbols[s.Symbol] {
+				d.Removed = append(d.Removed, s)
+			}
+		}
+	}
+
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Symbol < d.Added[j].Symbol })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Symbol < d.Removed[j].Symbol })
+	sort.Slice(d.Modified, func(i, j int) bool { return d.Modified[i].Symbol < d.Modified[j].Symbol })
+	return &d
+}
+
+// CompareSymbolInfo returns the fields which values differ between the before and after symbol information.
+// The fields are named after their JSON names
+func CompareSymbolInfo(before, after *types.SymbolInfo) []types.FieldChange {
+	fields := make([]types.FieldChange, 0)
+	b := reflect.ValueOf(before).Elem()
+	a := reflect.ValueOf(after).Elem()
+	for i := 0; i < b.NumField(); i++ {
+		if reflect.DeepEqual(b.Field(i).Interface(), a.Field(i).Interface()) {
+			continue
+		}
+		fields = append(fields, types.FieldChange{
+			Field:  fieldName(b.Type().Field(i)),
+			Before: b.Field(i).Interface(),
+			After:  a.Field(i).Interface()})
+	}
+	return fields
+}
+
+func fieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// IsEmpty returns true if the diff contains no changes
+func IsEmpty(d *types.SymbolsDiff) bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Events converts the diff into the list of change events, one event per added, removed or modified symbol
+func Events(d *types.SymbolsDiff) []types.ChangeEvent {
+	events := make([]types.ChangeEvent, 0, len(d.Added)+len(d.Removed)+len(d.Modified))
+	for i := range d.Added {
+		events = append(events, types.ChangeEvent{
+			Type:                 types.ChangeAdded,
+			ExchangeID:           d.ExchangeID,
+			Symbol:               d.Added[i].Symbol,
+			SnapshotTime:         d.ToSnapshotTime,
+			PreviousSnapshotTime: d.FromSnapshotTime,
+			After:                &d.Added[i]})
+	}
+	for i := range d.Removed {
+		events = append(events, types.ChangeEvent{
+			Type:                 types.ChangeRemoved,
+			ExchangeID:           d.ExchangeID,
+			Symbol:               d.Removed[i].Symbol,
+			SnapshotTime:         d.ToSnapshotTime,
+			PreviousSnapshotTime: d.FromSnapshotTime,
+			Before:               &d.Removed[i]})
+	}
+	for i := range d.Modified {
+		events = append(events, types.ChangeEvent{
+			Type:                 types.ChangeModified,
+			ExchangeID:           d.ExchangeID,
+			Symbol:               d.Modified[i].Symbol,
+			SnapshotTime:         d.ToSnapshotTime,
+			PreviousSnapshotTime: d.FromSnapshotTime,
+			Before:               &d.Modified[i].Before,
+			After:                &d.Modified[i].After,
+			Fields:               d.Modified[i].Fields})
+	}
+	return events
+}
@@ -0,0 +1,92 @@
+package changes
+
+import (
+	"testing"
+
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := types.ExchangeSymbols{
+		ExchangeID:   1,
+		SnapshotTime: 1000,
+		Symbols: []types.SymbolInfo{
+			{Symbol: "BTCUSDT", Status: "TRADING", BaseAsset: "BTC", QuoteAsset: "USDT"},
+			{Symbol: "ETHBTC", Status: "TRADING", BaseAsset: "ETH", QuoteAsset: "BTC"},
+			{Symbol: "XRPBTC", Status: "TRADING", BaseAsset: "XRP", QuoteAsset: "BTC"}}}
+	after := types.ExchangeSymbols{
+		ExchangeID:   1,
+		SnapshotTime: 2000,
+		Symbols: []types.SymbolInfo{
+			{Symbol: "BTCUSDT", Status: "TRADING", BaseAsset: "BTC", QuoteAsset: "USDT"},
+			{Symbol: "ETHBTC", Status: "BREAK", BaseAsset: "ETH", QuoteAsset: "BTC"},
+			{Symbol: "LTCBTC", Status: "TRADING", BaseAsset: "LTC", QuoteAsset: "BTC"}}}
+
+	d := Diff(&before, &after)
+	assert.Equal(t, int64(1000), d.FromSnapshotTime)
+	assert.Equal(t, int64(2000), d.ToSnapshotTime)
+	assert.Len(t, d.Added, 1)
+	assert.Equal(t, "LTCBTC", d.Added[0].Symbol)
+	assert.Len(t, d.Removed, 1)
+	assert.Equal(t, "XRPBTC", d.Removed[0].Symbol)
+	assert.Len(t, d.Modified, 1)
+	assert.Equal(t, "ETHBTC", d.Modified[0].Symbol)
+	assert.Equal(t, []types.FieldChange{{Field: "status", Before: "TRADING", After: "BREAK"}}, d.Modified[0].Fields)
+}
+
+func TestDiffWithoutBefore(t *testing.T) {
+	after := types.ExchangeSymbols{
+		ExchangeID:   2,
+		SnapshotTime: 2000,
+		Symbols:      []types.SymbolInfo{{Symbol: "tBTCUSD"}, {Symbol: "fUSD"}}}
+
+	d := Diff(nil, &after)
+	assert.Len(t, d.Added, 2)
+	assert.Empty(t, d.Removed)
+	assert.Empty(t, d.Modified)
+	assert.Equal(t, "fUSD", d.Added[0].Symbol)
+}
+
+func TestEvents(t *testing.T) {
+	before := types.ExchangeSymbols{
+		ExchangeID:   1,
+		SnapshotTime: 1000,
+		Symbols:      []types.SymbolInfo{{Symbol: "ETHBTC", QuotePrecision: 8}, {Symbol: "XRPBTC"}}}
+	after := types.ExchangeSymbols{
+		ExchangeID:   1,
+		SnapshotTime: 2000,
+		Symbols:      []types.SymbolInfo{{Symbol: "ETHBTC", QuotePrecision: 6}, {Symbol: "LTCBTC"}}}
+
+	events := Events(Diff(&before, &after))
+	assert.Len(t, events, 3)
+	assert.Equal(t, types.ChangeAdded, events[0].Type)
+	assert.Equal(t, "LTCBTC", events[0].Symbol)
+	assert.Nil(t, events[0].Before)
+	assert.Equal(t, types.ChangeRemoved, events[1].Type)
+	assert.Equal(t, "XRPBTC", events[1].Symbol)
+	assert.Nil(t, events[1].After)
+	assert.Equal(t, types.ChangeModified, events[2].Type)
+	assert.Equal(t, "quotePrecision", events[2].Fields[0].Field)
+	assert.Equal(t, int64(1000), events[2].PreviousSnapshotTime)
+}
+
+func TestFormatText(t *testing.T) {
+	before := types.ExchangeSymbols{
+		SnapshotTime: 1551704700000,
+		Symbols: []types.SymbolInfo{
+			{Symbol: "ETHBTC", Status: "TRADING", BaseAsset: "ETH", QuoteAsset: "BTC"},
+			{Symbol: "XRPBTC", Status: "TRADING", BaseAsset: "XRP", QuoteAsset: "BTC"}}}
+	after := types.ExchangeSymbols{
+		SnapshotTime: 1552309500000,
+		Symbols: []types.SymbolInfo{
+			{Symbol: "ETHBTC", Status: "BREAK", BaseAsset: "ETH", QuoteAsset: "BTC"},
+			{Symbol: "LTCBTC", Status: "TRADING", BaseAsset: "LTC", QuoteAsset: "BTC"}}}
+
+	expected := "binance: 2019-03-04T13:05:00Z -> 2019-03-11T13:05:00Z\n" +
+		"+ LTCBTC TRADING LTC/BTC\n" +
+		"- XRPBTC TRADING XRP/BTC\n" +
+		"~ ETHBTC status: TRADING -> BREAK\n" +
+		"1 added, 1 removed, 1 modified\n"
+	assert.Equal(t, expected, FormatText("binance", Diff(&before, &after)))
+}
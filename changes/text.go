@@ -0,0 +1,35 @@
+package changes
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// FormatText renders the diff in a human-readable text form, one line per change
+func FormatText(exchange string, d *types.SymbolsDiff) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s: %s -> %s\n", exchange, formatSnapshotTime(d.FromSnapshotTime), formatSnapshotTime(d.ToSnapshotTime))
+	for _, s := range d.Added {
+		fmt.Fprintf(&b, "+ %s %s %s/%s\n", s.Symbol, s.Status, s.BaseAsset, s.QuoteAsset)
+	}
+	for _, s := range d.Removed {
+		fmt.Fprintf(&b, "- %s %s %s/%s\n", s.Symbol, s.Status, s.BaseAsset, s.QuoteAsset)
+	}
+	for _, m := range d.Modified {
+		for _, f := range m.Fields {
+			fmt.Fprintf(&b, "~ %s %s: %v -> %v\n", m.Symbol, f.Field, f.Before, f.After)
+		}
+	}
+	fmt.Fprintf(&b, "%d added, %d removed, %d modified\n", len(d.Added), len(d.Removed), len(d.Modified))
+	return b.String()
+}
+
+func formatSnapshotTime(snapshotTime int64) string {
+	if snapshotTime == 0 {
+		return "none"
+	}
+	return time.Unix(0, snapshotTime*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheEntry type is one cached response
+type cacheEntry struct {
+	etag    string
+	body    []byte
+	fetched time.Time
+}
+
+// cache type keeps up to size responses by their URL evicting the least recently fetched ones
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*cacheEntry
+}
+
+func newCache(size int) *cache {
+	c := cache{
+		size:    size,
+		entries: make(map[string]*cacheEntry)}
+	return &c
+}
+
+func (c *cache) get(u string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[u]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	return *e, true
+}
+
+func (c *cache) put(u string, etag string, body []byte) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[u]; !ok && len(c.entries) >= c.size {
+		c.evictOldest()
+	}
+	c.entries[u] = &cacheEntry{etag: etag, body: body, fetched: time.Now()}
+}
+
+// touch marks the cached response of the URL as fetched now
+func (c *cache) touch(u string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[u]; ok {
+		e.fetched = time.Now()
+	}
+}
+
+func (c *cache) evictOldest() {
+	oldest := ""
+	for u, e := range c.entries {
+		if oldest == "" || e.fetched.Before(c.entries[oldest].fetched) {
+			oldest = u
+		}
+	}
+	delete(c.entries, oldest)
+}
+
+func (c *cache) urls() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	urls := make([]string, 0, len(c.entries))
+	for u := range c.entries {
+		urls = append(urls, u)
+	}
+	return urls
+}
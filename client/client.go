@@ -0,0 +1,255 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Config type contains the settings of the client
+type Config struct {
+	// HTTPClient sends the requests, http.DefaultClient is used if it is nil
+	HTTPClient *http.Client
+	// Retries is the number of times a failed GET request is retried
+	Retries int
+	// RetryBackoff is the wait before the first retry, it doubles on every next retry
+	RetryBackoff time.Duration
+	// CacheSize is the maximum number of cached responses, 0 disables the cache and the conditional requests
+	CacheSize int
+	// CacheTTL is the time the cached responses are returned without asking the server,
+	// afterwards they are revalidated with conditional requests. 0 revalidates on every call
+	CacheTTL time.Duration
+	// AdminToken is the bearer token sent with the requests of the admin API only
+	AdminToken string
+}
+
+// DefaultConfig returns the config with 3 retries and the cache of 256 responses revalidated on every call
+func DefaultConfig() *Config {
+	c := Config{
+		Retries:      3,
+		RetryBackoff: 200 * time.Millisecond,
+		CacheSize:    256}
+	return &c
+}
+
+// Error type is the error returned by the registry API
+type Error struct {
+	StatusCode int
+	APIError   types.APIError
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return fmt.Sprintf("registry API error %d %s: %s", e.StatusCode, e.APIError.Code, e.APIError.Message)
+}
+
+// Client type calls the registry API
+type Client struct {
+	baseURL    string
+	config     Config
+	httpClient *http.Client
+	cache      *cache
+}
+
+// NewClient creates a client of the registry API at baseURL, e.g. 'http://registry:8080'. The default config is used if config is nil
+func NewClient(baseURL string, config *Config) *Client {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	c := Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		config:     *config,
+		httpClient: config.HTTPClient,
+		cache:      newCache(config.CacheSize)}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	return &c
+}
+
+// url returns the URL of the path with the query
+func (c *Client) url(path string, query url.Values) string {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// get requests the path and decodes the JSON response into v using the cache
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	u := c.url(path, query)
+	body, err := c.fetch(ctx, u, false)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		glog.Errorf("Client.get: cannot decode the response of '%s' due to error %s", u, err)
+		return err
+	}
+	return nil
+}
+
+// fetch returns the body of the response of the URL. The fresh cached body is returned without a request,
+// the stale one is revalidated with its ETag. If revalidate is set, the cached body is always revalidated
+func (c *Client) fetch(ctx context.Context, u string, revalidate bool) ([]byte, error) {
+	entry, cached := c.cache.get(u)
+	if cached && !revalidate && c.config.CacheTTL > 0 && time.Since(entry.fetched) < c.config.CacheTTL {
+		return entry.body, nil
+	}
+	header := make(http.Header)
+	if cached && entry.etag != "" {
+		header.Set("If-None-Match", entry.etag)
+	}
+	resp, err := c.do(ctx, http.MethodGet, u, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		c.cache.touch(u)
+		return entry.body, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		glog.Errorf("Client.fetch: cannot read the response of '%s' due to error %s", u, err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeError(resp.StatusCode, body)
+	}
+	c.cache.put(u, resp.Header.Get("ETag"), body)
+	return body, nil
+}
+
+// send sends the JSON body with the method to the path and decodes the JSON response into v
+func (c *Client) send(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	u := c.url(path, nil)
+	data, err := json.Marshal(in)
+	if err != nil {
+		glog.Errorf("Client.send: cannot encode the request of '%s' due to error %s", u, err)
+		return err
+	}
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	resp, err := c.do(ctx, method, u, data, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		glog.Errorf("Client.send: cannot read the response of '%s' due to error %s", u, err)
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// do sends the request. The GET requests failed due to the network errors or the temporary server errors are retried
+func (c *Client) do(ctx context.Context, method string, u string, body []byte, header http.Header) (*http.Response, error) {
+	retries := 0
+	if method == http.MethodGet {
+		retries = c.config.Retries
+	}
+	backoff := c.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, u, reader)
+		if err != nil {
+			glog.Errorf("Client.do: cannot create request '%s %s' due to error %s", method, u, err)
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Accept", "application/json")
+		if c.config.AdminToken != "" && strings.HasPrefix(u, c.baseURL+"/admin/") {
+			req.Header.Set("Authorization", "Bearer "+c.config.AdminToken)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil && !isTemporary(resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt >= retries {
+			if err != nil {
+				glog.Errorf("Client.do: request '%s %s' failed due to error %s", method, u, err)
+			}
+			return resp, err
+		}
+		if err == nil {
+			glog.Warningf("Client.do: request '%s %s' failed with status %d, retrying", method, u, resp.StatusCode)
+			resp.Body.Close()
+		} else {
+			glog.Warningf("Client.do: request '%s %s' failed due to error %s, retrying", method, u, err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// isTemporary returns true if the request failed with the status may succeed when retried
+func isTemporary(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// decodeError returns the API error of the response
+func decodeError(status int, body []byte) error {
+	var r types.APIErrorResponse
+	if err := json.Unmarshal(body, &r); err != nil || r.Error.Code == "" {
+		r.Error = types.APIError{
+			Code:    types.ErrorCodeInternal,
+			Message: strings.TrimSpace(string(body))}
+	}
+	return &Error{StatusCode: status, APIError: r.Error}
+}
+
+// Refresh revalidates all the cached responses
+func (c *Client) Refresh(ctx context.Context) {
+	for _, u := range c.cache.urls() {
+		if _, err := c.fetch(ctx, u, true); err != nil {
+			glog.Warningf("Client.Refresh: cannot refresh '%s' due to error %s", u, err)
+		}
+	}
+}
+
+// RunRefresh revalidates all the cached responses every interval until the context is done
+func (c *Client) RunRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Refresh(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
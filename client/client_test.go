@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testConfig() *Config {
+	config := DefaultConfig()
+	config.RetryBackoff = time.Millisecond
+	return config
+}
+
+func TestGetSymbolsQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/symbols", r.URL.Path)
+		assert.Equal(t, "binance@bitfinex", r.URL.Query().Get("exchanges"))
+		assert.Equal(t, "BTC", r.URL.Query().Get("quote"))
+		assert.Equal(t, "2019-03-04", r.URL.Query().Get("date"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		json.NewEncoder(w).Encode(types.APIExchangesSymbols{
+			Exchanges:  []types.APIExchangeSymbols{{Exchange: "binance", Symbols: []types.APISymbolInfo{{Symbol: "binance-ETHBTC"}}}},
+			NextCursor: "next"})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, testConfig())
+	r, err := c.GetSymbols(context.Background(), &SymbolsQuery{
+		PointInTime: PointInTime{Date: "2019-03-04"},
+		Exchanges:   []string{"binance", "bitfinex"},
+		QuoteAssets: []string{"BTC"},
+		Limit:       10})
+	assert.NoError(t, err)
+	assert.Equal(t, "binance-ETHBTC", r.Exchanges[0].Symbols[0].Symbol)
+	assert.Equal(t, "next", r.NextCursor)
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(types.APIAssets{Assets: []types.APIAssetSummary{{Asset: "BTC"}}})
+	}))
+	defer server.Close()
+
+	r, err := NewClient(server.URL, testConfig()).GetAssets(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC", r.Assets[0].Asset)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestAPIError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(types.APIErrorResponse{Error: types.APIError{
+			Code:    types.ErrorCodeUnknownExchange,
+			Message: "exchange 'unknown' is not supported"}})
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, testConfig()).GetAsset(context.Background(), "BTC", []string{"unknown"})
+	apiErr, ok := err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, types.ErrorCodeUnknownExchange, apiErr.APIError.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestConditionalRequests(t *testing.T) {
+	var calls, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(types.APIInstrumentLookup{CanonicalID: "binance:spot:BTC/USDT"})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, testConfig())
+	for i := 0; i < 2; i++ {
+		r, err := c.LookupNative(context.Background(), "binance-BTCUSDT")
+		assert.NoError(t, err)
+		assert.Equal(t, "binance:spot:BTC/USDT", r.CanonicalID)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
+
+func TestCacheTTL(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(types.APIAssets{})
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.CacheTTL = time.Hour
+	c := NewClient(server.URL, config)
+	for i := 0; i < 3; i++ {
+		_, err := c.GetAssets(context.Background(), nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	c.Refresh(context.Background())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := newCache(2)
+	c.put("a", "", []byte("a"))
+	time.Sleep(time.Millisecond)
+	c.put("b", "", []byte("b"))
+	time.Sleep(time.Millisecond)
+	c.put("c", "", []byte("c"))
+	_, ok := c.get("a")
+	assert.False(t, ok)
+	_, ok = c.get("c")
+	assert.True(t, ok)
+}
+
+func TestReadEvents(t *testing.T) {
+	body := "id:1\nevent:change\ndata:{\"id\":1,\"type\":\"change\",\"exchange\":\"binance\"}\n\n" +
+		"id:2\nevent:snapshot\ndata:{\"id\":2,\"type\":\"snapshot\",\"exchange\":\"bitfinex\"}\n\n"
+	events := []types.APIStreamEvent{}
+	err := readEvents(bufio.NewScanner(strings.NewReader(body)), func(e *types.APIStreamEvent) error {
+		events = append(events, *e)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.APIStreamEvent{
+		{ID: 1, Type: types.StreamEventTypeChange, Exchange: "binance"},
+		{ID: 2, Type: types.StreamEventTypeSnapshot, Exchange: "bitfinex"}}, events)
+}
+
+func TestAdminTokenOnlyOnAdminAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/admin/") {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"version": 1})
+			return
+		}
+		assert.Empty(t, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(types.APIAssets{})
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.AdminToken = "secret"
+	c := NewClient(server.URL, config)
+	_, err := c.GetAssets(context.Background(), nil)
+	assert.NoError(t, err)
+	table, err := c.GetAssetAliases(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, table.Version)
+}
+
+func TestPointInTimeMilliseconds(t *testing.T) {
+	query := url.Values{}
+	PointInTime{At: time.Date(2019, 3, 4, 13, 5, 0, 123000000, time.UTC)}.encode(query)
+	assert.Equal(t, "2019-03-04T13:05:00.123Z", query.Get("at"))
+}
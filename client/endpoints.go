@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/etrubenok/make-trades-registry/assets"
+	"github.com/etrubenok/make-trades-registry/markets"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// GetSymbols returns the symbols of the exchanges passing the query
+func (c *Client) GetSymbols(ctx context.Context, q *SymbolsQuery) (*types.APIExchangesSymbols, error) {
+	var r types.APIExchangesSymbols
+	if err := c.get(ctx, "/symbols", q.encode(), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetSymbol returns the full information about the symbol given in format 'exchange-SYMBOL'
+func (c *Client) GetSymbol(ctx context.Context, id string, at PointInTime) (*types.APISymbolDetail, error) {
+	query := make(url.Values)
+	at.encode(query)
+	var r types.APISymbolDetail
+	if err := c.get(ctx, "/symbols/"+url.PathEscape(id), query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetSymbolsDiff returns the differences between the snapshots of the exchange at from and to. Zero to means now
+func (c *Client) GetSymbolsDiff(ctx context.Context, exchange string, from time.Time, to time.Time) (*types.APISymbolsDiff, error) {
+	query := make(url.Values)
+	query.Set("exchange", exchange)
+	setTime(query, "from", from)
+	setTime(query, "to", to)
+	var r types.APISymbolsDiff
+	if err := c.get(ctx, "/symbols/diff", query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// LookupNative returns the canonical identifier of the symbol given in format 'exchange-SYMBOL'
+func (c *Client) LookupNative(ctx context.Context, native string) (*types.APIInstrumentLookup, error) {
+	return c.lookupInstrument(ctx, "native", native)
+}
+
+// LookupCanonical returns the native symbol of the canonical identifier in format 'exchange:type:BASE/QUOTE'
+func (c *Client) LookupCanonical(ctx context.Context, canonical string) (*types.APIInstrumentLookup, error) {
+	return c.lookupInstrument(ctx, "canonical", canonical)
+}
+
+func (c *Client) lookupInstrument(ctx context.Context, name string, value string) (*types.APIInstrumentLookup, error) {
+	query := make(url.Values)
+	query.Set(name, value)
+	var r types.APIInstrumentLookup
+	if err := c.get(ctx, "/instruments", query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetCommonMarkets returns the markets with the quote assets listed on at least minVenues of the exchanges.
+// Empty exchanges mean all the exchanges, empty quotes mean all the quote assets and 0 minVenues means all the exchanges
+func (c *Client) GetCommonMarkets(ctx context.Context, exchanges []string, quotes []string, minVenues int) (*types.APICommonMarkets, error) {
+	query := make(url.Values)
+	setList(query, "exchanges", exchanges)
+	setList(query, "quote", quotes)
+	setInt(query, "min_venues", minVenues)
+	var r types.APICommonMarkets
+	if err := c.get(ctx, "/markets/common", query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetAssets returns all the assets of the exchanges. Empty exchanges mean all the exchanges
+func (c *Client) GetAssets(ctx context.Context, exchanges []string) (*types.APIAssets, error) {
+	query := make(url.Values)
+	setList(query, "exchanges", exchanges)
+	var r types.APIAssets
+	if err := c.get(ctx, "/assets", query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetAsset returns the markets of the exchanges where the asset is traded. Empty exchanges mean all the exchanges
+func (c *Client) GetAsset(ctx context.Context, asset string, exchanges []string) (*types.APIAssetMarkets, error) {
+	query := make(url.Values)
+	setList(query, "exchanges", exchanges)
+	var r types.APIAssetMarkets
+	if err := c.get(ctx, "/assets/"+url.PathEscape(asset), query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// FindConversionRoutes returns the shortest routes converting the asset from into the asset to on the exchanges.
+// Empty exchanges mean all the exchanges
+func (c *Client) FindConversionRoutes(ctx context.Context, from string, to string, exchanges []string, opts markets.RouteOptions) (*types.APIConversionRoutes, error) {
+	query := make(url.Values)
+	query.Set("from", from)
+	query.Set("to", to)
+	setList(query, "exchanges", exchanges)
+	setInt(query, "max_hops", opts.MaxHops)
+	setInt(query, "limit", opts.Limit)
+	query.Set("cross_exchange", strconv.FormatBool(opts.CrossExchange))
+	var r types.APIConversionRoutes
+	if err := c.get(ctx, "/conversions", query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListSnapshots returns one page of the snapshots of the exchange stored between from and to, the latest first.
+// Zero times and limit mean the server defaults, cursor is the next cursor of the previous page
+func (c *Client) ListSnapshots(ctx context.Context, exchange string, from time.Time, to time.Time, limit int, cursor string) (*types.APISnapshotsList, error) {
+	query := make(url.Values)
+	setTime(query, "from", from)
+	setTime(query, "to", to)
+	setInt(query, "limit", limit)
+	setString(query, "cursor", cursor)
+	var r types.APISnapshotsList
+	if err := c.get(ctx, "/exchanges/"+url.PathEscape(exchange)+"/snapshots", query, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetSnapshot returns the snapshot of the exchange stored at the snapshot time
+func (c *Client) GetSnapshot(ctx context.Context, exchange string, snapshotTime time.Time) (*types.APIExchangeSymbols, error) {
+	var r types.APIExchangeSymbols
+	if err := c.get(ctx, "/exchanges/"+url.PathEscape(exchange)+"/snapshots/"+formatTime(snapshotTime), nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetAssetAliases returns the current asset aliases table
+func (c *Client) GetAssetAliases(ctx context.Context) (*assets.Table, error) {
+	var r assets.Table
+	if err := c.get(ctx, "/admin/assets/aliases", nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// PutAssetAliases replaces the asset aliases table with the table of a greater version
+func (c *Client) PutAssetAliases(ctx context.Context, t *assets.Table) (*assets.Table, error) {
+	var r assets.Table
+	if err := c.send(ctx, http.MethodPut, "/admin/assets/aliases", t, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
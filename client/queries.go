@@ -0,0 +1,90 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etrubenok/make-trades-registry/filter"
+)
+
+// PointInTime type selects the snapshots: the latest ones before At if it is set, otherwise the latest ones of the
+// day Date in format 'yyyy-mm-dd', otherwise the latest ones
+type PointInTime struct {
+	At   time.Time
+	Date string
+}
+
+func (p PointInTime) encode(query url.Values) {
+	if !p.At.IsZero() {
+		query.Set("at", p.At.UTC().Format(time.RFC3339Nano))
+	} else if p.Date != "" {
+		query.Set("date", p.Date)
+	}
+}
+
+// SymbolsQuery type contains the filter, the projection and the page of the requested symbols. Empty fields match everything
+type SymbolsQuery struct {
+	PointInTime
+	Exchanges   []string
+	Symbols     []string
+	Statuses    []string
+	BaseAssets  []string
+	QuoteAssets []string
+	Types       []string
+	Prefix      string
+	Regex       string
+	Fields      []string
+	Limit       int
+	Cursor      string
+}
+
+func (q *SymbolsQuery) encode() url.Values {
+	query := make(url.Values)
+	if q == nil {
+		return query
+	}
+	q.PointInTime.encode(query)
+	setList(query, "exchanges", q.Exchanges)
+	setList(query, "symbols", q.Symbols)
+	setList(query, "status", q.Statuses)
+	setList(query, "base", q.BaseAssets)
+	setList(query, "quote", q.QuoteAssets)
+	setList(query, "type", q.Types)
+	setList(query, "fields", q.Fields)
+	setString(query, "prefix", q.Prefix)
+	setString(query, "regex", q.Regex)
+	setString(query, "cursor", q.Cursor)
+	setInt(query, "limit", q.Limit)
+	return query
+}
+
+func setList(query url.Values, name string, values []string) {
+	if len(values) > 0 {
+		query.Set(name, strings.Join(values, filter.Separator))
+	}
+}
+
+func setString(query url.Values, name string, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
+
+func setInt(query url.Values, name string, value int) {
+	if value > 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
+
+func setTime(query url.Values, name string, value time.Time) {
+	if !value.IsZero() {
+		query.Set(name, formatTime(value))
+	}
+}
+
+// formatTime formats the time as the number of milliseconds since epoch
+func formatTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
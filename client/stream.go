@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// StreamSymbols receives the snapshot and change events of the symbols passing the query and calls handle for every event
+// until the context is done, the stream ends or handle returns an error. Only the filter of the query is used.
+// If lastEventID is not 0, the stream resumes after that event
+func (c *Client) StreamSymbols(ctx context.Context, q *SymbolsQuery, lastEventID int64, handle func(*types.APIStreamEvent) error) error {
+	query := q.encode()
+	query.Del("at")
+	query.Del("date")
+	u := c.url("/stream/symbols", query)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		glog.Errorf("Client.StreamSymbols: cannot create request '%s' due to error %s", u, err)
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(lastEventID, 10))
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		glog.Errorf("Client.StreamSymbols: request '%s' failed due to error %s", u, err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return decodeError(resp.StatusCode, body)
+	}
+	return readEvents(bufio.NewScanner(resp.Body), handle)
+}
+
+// readEvents reads the server-sent events carrying the stream events in their data
+func readEvents(scanner *bufio.Scanner, handle func(*types.APIStreamEvent) error) error {
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	data := make([]string, 0)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			if strings.HasPrefix(line, "data:") {
+				data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+		var e types.APIStreamEvent
+		if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &e); err != nil {
+			glog.Errorf("readEvents: cannot decode event due to error %s", err)
+			return err
+		}
+		data = data[:0]
+		if err := handle(&e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
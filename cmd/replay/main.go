@@ -0,0 +1,174 @@
+// Command replay regenerates the snapshots of the symbols from the archived raw responses of the exchanges
+// with the current parsing, e.g. after a parsing bug is fixed. The snapshots keep the times of the original fetches,
+// so saving them into Cassandra overwrites the snapshots derived from the same responses
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/archive"
+	"github.com/etrubenok/make-trades-registry/assets"
+	"github.com/etrubenok/make-trades-registry/core"
+	"github.com/etrubenok/make-trades-registry/db"
+	"github.com/etrubenok/make-trades-registry/fetchers"
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-registry/validation"
+)
+
+var (
+	archiveDir       = flag.String("archive-dir", "", "directory the raw responses are archived in")
+	exchanges        = flag.String("exchanges", "binance,bitfinex", "comma separated list of the exchanges replayed")
+	from             = flag.String("from", "", "replay the responses fetched at or after this date (YYYY-MM-DD) or time (RFC 3339), required")
+	to               = flag.String("to", "", "replay the responses fetched at or before this date (YYYY-MM-DD, the whole day) or time (RFC 3339), up to the last one if not given")
+	assetAliasesFile = flag.String("asset-aliases", "", "JSON file with the asset aliases table the symbols are normalised with, the built-in table is used if not given")
+	output           = flag.String("output", "", "JSON file the regenerated snapshots are written to, usable as the seed file of the registry")
+	cassandraHosts   = flag.String("cassandra-hosts", "", "comma separated list of Cassandra hosts the regenerated snapshots are saved to, nothing is saved if not given")
+	minSymbols       = flag.Int("min-symbols", validation.DefaultRules.MinSymbols, "minimum number of symbols in a snapshot, the smaller snapshots are rejected")
+	maxRemoved       = flag.Float64("max-removed-percent", validation.DefaultRules.MaxRemovedPercent, "maximum percentage of the symbols removed since the previous snapshot, the snapshots removing more are rejected")
+	confirmRemovals  = flag.Int("confirm-removals", validation.DefaultRules.ConfirmRemovals, "number of consecutive snapshots agreeing with each other after which the removal of more than max-removed-percent symbols is accepted, 0 never accepts it")
+	force            = flag.Bool("force", false, "keep the snapshots violating the validation rules, they are skipped by default")
+)
+
+// parseTime parses the flag value as a date or a time. The end of the day is returned for a date if endOfDay is true
+func parseTime(value string, fallback time.Time, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			return t.AddDate(0, 0, 1).Add(-time.Millisecond), nil
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// replay parses the archived responses of the exchange fetched between fromTime and toTime and passes every snapshot
+// to save. The snapshots are checked by the same guard with the same rules as the fetched ones, the rejected ones are
+// skipped unless force is true. It returns the number of the saved snapshots
+func replay(a *archive.Archive, exchange string, fromTime, toTime time.Time, rules validation.Rules, force bool,
+	save func(snapshot *types.ExchangeSymbols) error) (int, error) {
+	fetcher, err := fetchers.FetcherFactory(exchange)
+	if err != nil {
+		return 0, err
+	}
+	responses, err := a.List(exchange, fromTime, toTime)
+	if err != nil {
+		glog.Errorf("replay: cannot list the archived responses of exchange '%s' due to error %s", exchange, err)
+		return 0, err
+	}
+	guard := validation.NewGuard(rules, validation.NewMemoryQuarantine(1), nil)
+	var previous *types.ExchangeSymbols
+	saved := 0
+	for i := range responses {
+		r := &responses[i]
+		raw, err := a.Load(r)
+		if err != nil {
+			glog.Errorf("replay: cannot load the response of exchange '%s' fetched at %d due to error %s", exchange, r.FetchedAt, err)
+			continue
+		}
+		snapshot, err := fetcher.ParseSymbols(raw, time.Unix(0, r.FetchedAt*int64(time.Millisecond)))
+		if err != nil {
+			glog.Errorf("replay: cannot parse the response of exchange '%s' fetched at %d due to error %s", exchange, r.FetchedAt, err)
+			continue
+		}
+		if force {
+			if violations := validation.Validate(snapshot, previous, rules); len(violations) > 0 {
+				glog.Warningf("replay: the snapshot of exchange '%s' taken at %d violates %+v, it is kept as forced",
+					exchange, snapshot.SnapshotTime, violations)
+			}
+		} else if passed := guard.Check(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{*snapshot}}); len(passed.Exchanges) == 0 {
+			continue
+		}
+		if err := save(snapshot); err != nil {
+			return saved, err
+		}
+		saved++
+		previous = snapshot
+	}
+	glog.Infof("replay: regenerated %d snapshots of exchange '%s' from %d archived responses", saved, exchange, len(responses))
+	return saved, nil
+}
+
+func main() {
+	flag.Parse()
+	if *archiveDir == "" {
+		glog.Fatal("main: the archive directory is required")
+	}
+	// The archive is listed day by day, so the replay needs a start
+	if *from == "" {
+		glog.Fatal("main: the start of the replay is required")
+	}
+	fromTime, err := parseTime(*from, time.Time{}, false)
+	if err != nil {
+		glog.Fatalf("main: cannot parse the start of the replay '%s' due to error %s", *from, err)
+	}
+	toTime, err := parseTime(*to, time.Now(), true)
+	if err != nil {
+		glog.Fatalf("main: cannot parse the end of the replay '%s' due to error %s", *to, err)
+	}
+
+	if *assetAliasesFile != "" {
+		t, err := assets.LoadFile(*assetAliasesFile)
+		if err != nil {
+			glog.Fatalf("main: cannot load asset aliases from file '%s' due to error %s", *assetAliasesFile, err)
+		}
+		assets.Default = assets.NewRegistry(t)
+	}
+
+	store, err := archive.NewFileStore(*archiveDir)
+	if err != nil {
+		glog.Fatalf("main: cannot open the archive in directory '%s' due to error %s", *archiveDir, err)
+	}
+	a := archive.NewArchive(store)
+
+	var importer db.DBImporter
+	if *cassandraHosts != "" {
+		cluster := gocql.NewCluster(strings.Split(*cassandraHosts, ",")...)
+		cluster.Consistency = gocql.Quorum
+		session, err := cluster.CreateSession()
+		if err != nil {
+			glog.Fatalf("main: cannot connect to Cassandra hosts '%s' due to error %s", *cassandraHosts, err)
+		}
+		defer session.Close()
+		importer = db.NewDBImporter(session)
+	}
+
+	// The snapshots are kept in memory only to be written into the output file
+	all := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0)}
+	save := func(snapshot *types.ExchangeSymbols) error {
+		if importer != nil {
+			if err := importer.SaveSymbolsSnapshots(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{*snapshot}}); err != nil {
+				glog.Errorf("main: cannot save the snapshot taken at %d due to error %s", snapshot.SnapshotTime, err)
+				return err
+			}
+		}
+		if *output != "" {
+			all.Exchanges = append(all.Exchanges, *snapshot)
+		}
+		return nil
+	}
+	rules := validation.Rules{MinSymbols: *minSymbols, MaxRemovedPercent: *maxRemoved, ConfirmRemovals: *confirmRemovals}
+	replayed := 0
+	for _, exchange := range strings.Split(*exchanges, ",") {
+		saved, err := replay(a, exchange, fromTime, toTime, rules, *force, save)
+		if err != nil {
+			glog.Fatalf("main: cannot replay exchange '%s' due to error %s", exchange, err)
+		}
+		replayed += saved
+	}
+
+	if *output != "" {
+		if err := core.SaveFile(*output, &all); err != nil {
+			glog.Fatalf("main: cannot write the snapshots into file '%s' due to error %s", *output, err)
+		}
+	}
+	glog.Infof("main: replayed %d snapshots", replayed)
+	glog.Flush()
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/fetchers"
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-types/registry"
+)
+
+// Snapshot type is an immutable view of the current symbols of all the exchanges of the registry
+type Snapshot struct {
+	symbols     types.ExchangesSymbols
+	exchanges   map[string]*types.ExchangeSymbols
+	byID        map[int]*types.ExchangeSymbols
+	bySymbol    map[string]*types.SymbolInfo
+	byCanonical map[string]*types.SymbolInfo
+}
+
+// newSnapshot creates the view of the snapshots of the exchanges
+func newSnapshot(exchanges map[string]*types.ExchangeSymbols) *Snapshot {
+	s := Snapshot{
+		symbols:     types.ExchangesSymbols{Exchanges: make([]types.ExchangeSymbols, 0, len(exchanges))},
+		exchanges:   exchanges,
+		byID:        make(map[int]*types.ExchangeSymbols, len(exchanges)),
+		bySymbol:    make(map[string]*types.SymbolInfo),
+		byCanonical: make(map[string]*types.SymbolInfo)}
+	names := make([]string, 0, len(exchanges))
+	for exchange := range exchanges {
+		names = append(names, exchange)
+	}
+	sort.Strings(names)
+	for _, exchange := range names {
+		e := exchanges[exchange]
+		s.symbols.Exchanges = append(s.symbols.Exchanges, *e)
+		s.byID[e.ExchangeID] = e
+		for i := range e.Symbols {
+			symbol := &e.Symbols[i]
+			s.bySymbol[exchange+"-"+symbol.Symbol] = symbol
+			canonical := types.NewInstrumentID(exchange, symbol).String()
+			if _, ok := s.byCanonical[canonical]; !ok {
+				s.byCanonical[canonical] = symbol
+			}
+		}
+	}
+	return &s
+}
+
+// Symbols returns the snapshots of all the exchanges. The result must not be modified
+func (s *Snapshot) Symbols() *types.ExchangesSymbols {
+	return &s.symbols
+}
+
+// Exchange returns the snapshot of the exchange. The result must not be modified
+func (s *Snapshot) Exchange(exchange string) (*types.ExchangeSymbols, bool) {
+	e, ok := s.exchanges[exchange]
+	return e, ok
+}
+
+// ExchangesByID returns the snapshots of the exchanges in the order of the exchange ids if all of them are known
+func (s *Snapshot) ExchangesByID(exchangeIDs []int) (*types.ExchangesSymbols, bool) {
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0, len(exchangeIDs))}
+	for _, id := range exchangeIDs {
+		e, ok := s.byID[id]
+		if !ok {
+			return nil, false
+		}
+		r.Exchanges = append(r.Exchanges, *e)
+	}
+	return &r, true
+}
+
+// Symbol returns the symbol of the exchange by its native name. The result must not be modified
+func (s *Snapshot) Symbol(exchange string, symbol string) (*types.SymbolInfo, bool) {
+	info, ok := s.bySymbol[exchange+"-"+symbol]
+	return info, ok
+}
+
+// Instrument returns the symbol by its canonical identifier in format 'exchange:type:BASE/QUOTE'. The result must not be modified
+func (s *Snapshot) Instrument(canonicalID string) (*types.SymbolInfo, bool) {
+	id, err := types.ParseInstrumentID(canonicalID)
+	if err != nil {
+		return nil, false
+	}
+	info, ok := s.byCanonical[id.String()]
+	return info, ok
+}
+
+// Registry type holds the current symbols of the exchanges in memory. The reads do not take locks
+type Registry struct {
+	exchanges []string
+	current   atomic.Value
+	mu        sync.Mutex
+}
+
+// NewRegistry creates an empty registry of the exchanges
+func NewRegistry(exchanges []string) *Registry {
+	r := Registry{exchanges: exchanges}
+	r.current.Store(newSnapshot(make(map[string]*types.ExchangeSymbols)))
+	return &r
+}
+
+// Current returns the current symbols
+func (r *Registry) Current() *Snapshot {
+	return r.current.Load().(*Snapshot)
+}
+
+// Update replaces the symbols of the exchanges of the snapshots unless the current ones are newer
+func (r *Registry) Update(snapshots *types.ExchangesSymbols) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := r.Current()
+	exchanges := make(map[string]*types.ExchangeSymbols, len(current.exchanges)+len(snapshots.Exchanges))
+	for exchange, e := range current.exchanges {
+		exchanges[exchange] = e
+	}
+	updated := false
+	for i := range snapshots.Exchanges {
+		e := snapshots.Exchanges[i]
+		exchange, err := registry.GetExchangeNameByID(e.ExchangeID)
+		if err != nil {
+			glog.Errorf("Registry.Update: cannot get exchange name by id '%d' due to error %s", e.ExchangeID, err)
+			continue
+		}
+		if previous, ok := exchanges[exchange]; ok && previous.SnapshotTime > e.SnapshotTime {
+			continue
+		}
+		exchanges[exchange] = &e
+		updated = true
+	}
+	if updated {
+		r.current.Store(newSnapshot(exchanges))
+	}
+}
+
+// Seed loads the initial symbols of the exchanges of the registry from the seed
+func (r *Registry) Seed(seed Seed) error {
+	snapshots, err := seed.Load(r.exchanges)
+	if err != nil {
+		glog.Errorf("Registry.Seed: cannot load the snapshots of exchanges %v due to error %s", r.exchanges, err)
+		return err
+	}
+	r.Update(snapshots)
+	return nil
+}
+
+// Guard is an interface of the checks of the fetched snapshots, it returns the ones allowed into the registry
+type Guard interface {
+	Check(snapshots *types.ExchangesSymbols) *types.ExchangesSymbols
+}
+
+// Run starts fetching the symbols of the exchanges of the registry. Every fetched snapshot passed by the guard
+// updates the registry and then it is sent to results if results is not nil. guard may be nil to accept all the snapshots.
+// The fetching stops when stop is closed, results is closed then
+func (r *Registry) Run(results chan<- types.ExchangesSymbols, guard Guard, stop <-chan struct{}) {
+	r.run(fetchers.NewFetchJob(), results, guard, stop)
+}
+
+// run updates the registry with the snapshots fetched by the job until stop is closed
+func (r *Registry) run(job fetchers.FetchJob, results chan<- types.ExchangesSymbols, guard Guard, stop <-chan struct{}) {
+	fetched := make(chan types.ExchangesSymbols)
+	job.Init(r.exchanges, fetched)
+	go func() {
+		defer func() {
+			job.Stop()
+			if results != nil {
+				close(results)
+			}
+		}()
+		for {
+			var snapshots types.ExchangesSymbols
+			select {
+			case snapshots = <-fetched:
+			case <-stop:
+				return
+			}
+			if guard != nil {
+				snapshots = *guard.Check(&snapshots)
+			}
+			if len(snapshots.Exchanges) == 0 {
+				continue
+			}
+			r.Update(&snapshots)
+			if results == nil {
+				continue
+			}
+			select {
+			case results <- snapshots:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/etrubenok/make-trades-types/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testSnapshots(t *testing.T, snapshotTime int64) *types.ExchangesSymbols {
+	binanceID, err := registry.GetExchangeID("binance")
+	assert.NoError(t, err)
+	bitfinexID, err := registry.GetExchangeID("bitfinex")
+	assert.NoError(t, err)
+	return &types.ExchangesSymbols{
+		Exchanges: []types.ExchangeSymbols{
+			{ExchangeID: bitfinexID, SnapshotTime: snapshotTime, Symbols: []types.SymbolInfo{
+				{Symbol: "tBTCUSD", Type: "spot", BaseAsset: "BTC", QuoteAsset: "USD", BaseAssetPrecision: 5}}},
+			{ExchangeID: binanceID, SnapshotTime: snapshotTime, Symbols: []types.SymbolInfo{
+				{Symbol: "ETHBTC", Type: "spot", BaseAsset: "ETH", QuoteAsset: "BTC", BaseAssetPrecision: 8}}}}}
+}
+
+func TestRegistryLookups(t *testing.T) {
+	r := NewRegistry([]string{"binance", "bitfinex"})
+	_, ok := r.Current().Symbol("binance", "ETHBTC")
+	assert.False(t, ok)
+
+	r.Update(testSnapshots(t, 1000))
+	current := r.Current()
+	s, ok := current.Symbol("binance", "ETHBTC")
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), s.BaseAssetPrecision)
+
+	s, ok = current.Instrument("bitfinex:spot:btc/usd")
+	assert.True(t, ok)
+	assert.Equal(t, "tBTCUSD", s.Symbol)
+
+	e, ok := current.Exchange("bitfinex")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), e.SnapshotTime)
+
+	assert.Equal(t, 2, len(current.Symbols().Exchanges))
+	binanceID, _ := registry.GetExchangeID("binance")
+	byID, ok := current.ExchangesByID([]int{binanceID})
+	assert.True(t, ok)
+	assert.Equal(t, "ETHBTC", byID.Exchanges[0].Symbols[0].Symbol)
+	_, ok = current.ExchangesByID([]int{-1})
+	assert.False(t, ok)
+}
+
+func TestRegistryUpdateKeepsNewer(t *testing.T) {
+	r := NewRegistry([]string{"binance", "bitfinex"})
+	r.Update(testSnapshots(t, 2000))
+	previous := r.Current()
+
+	older := testSnapshots(t, 1000)
+	older.Exchanges[1].Symbols[0].BaseAssetPrecision = 2
+	r.Update(older)
+	assert.True(t, previous == r.Current())
+
+	newer := testSnapshots(t, 3000)
+	newer.Exchanges = newer.Exchanges[1:]
+	newer.Exchanges[0].Symbols[0].BaseAssetPrecision = 4
+	r.Update(newer)
+	s, _ := r.Current().Symbol("binance", "ETHBTC")
+	assert.Equal(t, int64(4), s.BaseAssetPrecision)
+	_, ok := r.Current().Symbol("bitfinex", "tBTCUSD")
+	assert.True(t, ok)
+
+	// The snapshots taken before the update are not changed
+	s, _ = previous.Symbol("binance", "ETHBTC")
+	assert.Equal(t, int64(8), s.BaseAssetPrecision)
+}
+
+func TestFileSeed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "core")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshots.json")
+	assert.NoError(t, SaveFile(path, testSnapshots(t, 1000)))
+
+	r := NewRegistry([]string{"binance"})
+	assert.NoError(t, r.Seed(NewFileSeed(path)))
+	_, ok := r.Current().Symbol("binance", "ETHBTC")
+	assert.True(t, ok)
+	_, ok = r.Current().Symbol("bitfinex", "tBTCUSD")
+	assert.False(t, ok)
+
+	assert.Error(t, r.Seed(NewFileSeed(filepath.Join(dir, "missing.json"))))
+}
+
+type testJob struct {
+	results chan<- types.ExchangesSymbols
+	stopped chan struct{}
+}
+
+func (j *testJob) Init(exchanges []string, results chan<- types.ExchangesSymbols) {
+	j.results = results
+}
+
+func (j *testJob) Stop() {
+	close(j.stopped)
+}
+
+func TestRegistryRunStop(t *testing.T) {
+	r := NewRegistry([]string{"binance", "bitfinex"})
+	job := &testJob{stopped: make(chan struct{})}
+	results := make(chan types.ExchangesSymbols)
+	stop := make(chan struct{})
+	r.run(job, results, nil, stop)
+
+	job.results <- *testSnapshots(t, 1000)
+	snapshots := <-results
+	assert.Len(t, snapshots.Exchanges, 2)
+	_, ok := r.Current().Symbol("binance", "ETHBTC")
+	assert.True(t, ok)
+
+	close(stop)
+	_, ok = <-results
+	assert.False(t, ok)
+	<-job.stopped
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/db"
+	"github.com/etrubenok/make-trades-registry/fetchers"
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-types/registry"
+)
+
+// Seed is the interface of the sources of the symbols the registry starts with
+type Seed interface {
+	Load(exchanges []string) (*types.ExchangesSymbols, error)
+}
+
+// DBSeed type loads the latest snapshots stored in the DB
+type DBSeed struct {
+	loader db.DBLoader
+}
+
+// NewDBSeed creates the seed loading the latest snapshots with the loader
+func NewDBSeed(loader db.DBLoader) Seed {
+	s := DBSeed{loader: loader}
+	return &s
+}
+
+// Load loads the latest snapshots of the exchanges
+func (s *DBSeed) Load(exchanges []string) (*types.ExchangesSymbols, error) {
+	exchangeIDs := make([]int, 0, len(exchanges))
+	for _, e := range exchanges {
+		exchangeID, err := registry.GetExchangeID(e)
+		if err != nil {
+			glog.Errorf("DBSeed.Load: cannot get exchange id for exchange '%s' due to error %s", e, err)
+			return nil, err
+		}
+		exchangeIDs = append(exchangeIDs, exchangeID)
+	}
+	return s.loader.LoadSymbolsSnapshots(exchangeIDs, func() (int, int, int, error) {
+		year, month, day := fetchers.GetYearMonthDay(time.Now().UnixNano() / int64(time.Millisecond))
+		return year, month, day, nil
+	})
+}
+
+// FileSeed type reads the snapshots from a JSON file
+type FileSeed struct {
+	path string
+}
+
+// NewFileSeed creates the seed reading the snapshots from the JSON file written by SaveFile
+func NewFileSeed(path string) Seed {
+	s := FileSeed{path: path}
+	return &s
+}
+
+// Load reads the snapshots of the exchanges from the file, the snapshots of the other exchanges are skipped
+func (s *FileSeed) Load(exchanges []string) (*types.ExchangesSymbols, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		glog.Errorf("FileSeed.Load: cannot read snapshots file '%s' due to error %s", s.path, err)
+		return nil, err
+	}
+	var snapshots types.ExchangesSymbols
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		glog.Errorf("FileSeed.Load: cannot parse snapshots file '%s' due to error %s", s.path, err)
+		return nil, err
+	}
+	wanted := make(map[int]bool, len(exchanges))
+	for _, e := range exchanges {
+		if exchangeID, err := registry.GetExchangeID(e); err == nil {
+			wanted[exchangeID] = true
+		}
+	}
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0, len(snapshots.Exchanges))}
+	for _, e := range snapshots.Exchanges {
+		if wanted[e.ExchangeID] {
+			r.Exchanges = append(r.Exchanges, e)
+		}
+	}
+	return &r, nil
+}
+
+// SaveFile writes the snapshots into the JSON file readable by FileSeed
+func SaveFile(path string, snapshots *types.ExchangesSymbols) error {
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		glog.Errorf("SaveFile: cannot marshal the snapshots due to error %s", err)
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		glog.Errorf("SaveFile: cannot write snapshots file '%s' due to error %s", path, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// CacheStats type contains the number of lookups served from the cache and passed to the DB
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+	Latest  int   `json:"latest"`
+}
+
+// cacheEntry is one cached lookup of a snapshot
+type cacheEntry struct {
+	key      string
+	snapshot *types.ExchangeSymbols
+}
+
+// CachingDBLoader is an implementation of DBLoader interface keeping the latest snapshot of every exchange in memory
+// and the lookups of the snapshots which cannot change anymore in an LRU cache in front of another DBLoader
+type CachingDBLoader struct {
+	loader   DBLoader
+	capacity int
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	latest  map[int]*types.ExchangeSymbols
+	entries map[string]*list.Element
+	order   *list.List
+	hits    int64
+	misses  int64
+}
+
+// NewCachingDBLoader instantiates CachingDBLoader object. capacity is the maximum number of the historical lookups cached
+func NewCachingDBLoader(loader DBLoader, capacity int) *CachingDBLoader {
+	l := CachingDBLoader{
+		loader:   loader,
+		capacity: capacity,
+		now:      time.Now,
+		latest:   make(map[int]*types.ExchangeSymbols),
+		entries:  make(map[string]*list.Element),
+		order:    list.New()}
+	return &l
+}
+
+// Update makes the snapshots the latest ones of their exchanges unless newer ones are already known
+func (l *CachingDBLoader) Update(snapshots *types.ExchangesSymbols) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for i := range snapshots.Exchanges {
+		e := snapshots.Exchanges[i]
+		if current, ok := l.latest[e.ExchangeID]; ok && current.SnapshotTime >= e.SnapshotTime {
+			continue
+		}
+		l.latest[e.ExchangeID] = &e
+	}
+}
+
+// Stats returns the statistics of the cache
+func (l *CachingDBLoader) Stats() CacheStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return CacheStats{
+		Hits:    l.hits,
+		Misses:  l.misses,
+		Entries: l.order.Len(),
+		Latest:  len(l.latest)}
+}
+
+// lookup returns the latest snapshot of the exchange if accept returns true for it, otherwise the cached lookup by the key.
+// The result is counted as a hit or a miss
+func (l *CachingDBLoader) lookup(exchangeID int, accept func(latest *types.ExchangeSymbols) bool, key string) (*types.ExchangeSymbols, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if latest, ok := l.latest[exchangeID]; ok && accept(latest) {
+		l.hits++
+		return latest, true
+	}
+	if element, ok := l.entries[key]; ok {
+		l.order.MoveToFront(element)
+		l.hits++
+		return element.Value.(*cacheEntry).snapshot, true
+	}
+	l.misses++
+	return nil, false
+}
+
+// put caches the lookup evicting the least recently used one if the cache is full
+func (l *CachingDBLoader) put(key string, snapshot *types.ExchangeSymbols) {
+	if l.capacity <= 0 {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if element, ok := l.entries[key]; ok {
+		element.Value.(*cacheEntry).snapshot = snapshot
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&cacheEntry{key: key, snapshot: snapshot})
+	if l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// LoadSymbolsSnapshots loads the latest snapshots of symbols for the exchangeIDs on the date returned by getDate.
+// The latest snapshot is served if it is taken on that date, the lookups of the past days are cached
+func (l *CachingDBLoader) LoadSymbolsSnapshots(exchangeIDs []int, getDate func() (int, int, int, error)) (*types.ExchangesSymbols, error) {
+	year, month, day, err := getDate()
+	if err != nil {
+		glog.Errorf("CachingDBLoader.LoadSymbolsSnapshots: cannot get year, month and day due to error '%s'", err)
+		return nil, err
+	}
+	nextDay := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	pastDay := !l.now().Before(nextDay)
+
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, len(exchangeIDs))}
+	for i, e := range exchangeIDs {
+		key := fmt.Sprintf("day:%d:%04d-%02d-%02d", e, year, month, day)
+		snapshot, ok := l.lookup(e, func(latest *types.ExchangeSymbols) bool {
+			y, m, d := GetYearMonthDayUTC(time.Unix(0, latest.SnapshotTime*int64(time.Millisecond)))
+			return y == year && m == month && d == day
+		}, key)
+		if !ok {
+			snapshots, err := l.loader.LoadSymbolsSnapshots([]int{e}, getDate)
+			if err != nil {
+				return nil, err
+			}
+			snapshot = &snapshots.Exchanges[0]
+			if pastDay {
+				l.put(key, snapshot)
+			}
+		}
+		r.Exchanges[i] = *snapshot
+	}
+	return &r, nil
+}
+
+// LoadSymbolsSnapshotsAt loads for every exchange the last snapshot of symbols taken at or before the given time.
+// The latest snapshot is served if it is taken before that time, the lookups of the past times are cached
+func (l *CachingDBLoader) LoadSymbolsSnapshotsAt(exchangeIDs []int, at time.Time) (*types.ExchangesSymbols, error) {
+	atMs := at.UnixNano() / int64(time.Millisecond)
+	past := at.Before(l.now())
+
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, len(exchangeIDs))}
+	for i, e := range exchangeIDs {
+		key := fmt.Sprintf("at:%d:%d", e, atMs)
+		snapshot, ok := l.lookup(e, func(latest *types.ExchangeSymbols) bool {
+			return latest.SnapshotTime <= atMs
+		}, key)
+		if !ok {
+			snapshots, err := l.loader.LoadSymbolsSnapshotsAt([]int{e}, at)
+			if err != nil {
+				return nil, err
+			}
+			snapshot = &snapshots.Exchanges[0]
+			if past {
+				l.put(key, snapshot)
+			}
+		}
+		r.Exchanges[i] = *snapshot
+	}
+	return &r, nil
+}
+
+// ListSnapshots lists the snapshots of the exchange, the lists are not cached
+func (l *CachingDBLoader) ListSnapshots(exchangeID int, from, to time.Time, limit int) ([]types.ExchangeSymbols, error) {
+	return l.loader.ListSnapshots(exchangeID, from, to, limit)
+}
+
+// LoadSnapshot loads the snapshot of the exchange taken exactly at snapshotTime. The stored snapshots never change,
+// so all of them are cached
+func (l *CachingDBLoader) LoadSnapshot(exchangeID int, snapshotTime time.Time) (*types.ExchangeSymbols, error) {
+	snapshotMs := snapshotTime.UnixNano() / int64(time.Millisecond)
+	key := fmt.Sprintf("snapshot:%d:%d", exchangeID, snapshotMs)
+	snapshot, ok := l.lookup(exchangeID, func(latest *types.ExchangeSymbols) bool {
+		return latest.SnapshotTime == snapshotMs
+	}, key)
+	if ok {
+		return snapshot, nil
+	}
+	snapshot, err := l.loader.LoadSnapshot(exchangeID, snapshotTime)
+	if err != nil {
+		return nil, err
+	}
+	l.put(key, snapshot)
+	return snapshot, nil
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// testLoader serves the snapshots of one exchange and counts the loads
+type testLoader struct {
+	snapshots []types.ExchangeSymbols
+	loads     int
+}
+
+func (l *testLoader) last(before int64) (*types.ExchangeSymbols, error) {
+	l.loads++
+	var r *types.ExchangeSymbols
+	for i := range l.snapshots {
+		if l.snapshots[i].SnapshotTime <= before {
+			r = &l.snapshots[i]
+		}
+	}
+	if r == nil {
+		return nil, gocql.ErrNotFound
+	}
+	return r, nil
+}
+
+func (l *testLoader) LoadSymbolsSnapshots(exchangeIDs []int, getDate func() (int, int, int, error)) (*types.ExchangesSymbols, error) {
+	year, month, day, _ := getDate()
+	end := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	s, err := l.last(end.UnixNano()/int64(time.Millisecond) - 1)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{*s}}, nil
+}
+
+func (l *testLoader) LoadSymbolsSnapshotsAt(exchangeIDs []int, at time.Time) (*types.ExchangesSymbols, error) {
+	s, err := l.last(at.UnixNano() / int64(time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+	return &types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{*s}}, nil
+}
+
+func (l *testLoader) ListSnapshots(exchangeID int, from, to time.Time, limit int) ([]types.ExchangeSymbols, error) {
+	l.loads++
+	return l.snapshots, nil
+}
+
+func (l *testLoader) LoadSnapshot(exchangeID int, snapshotTime time.Time) (*types.ExchangeSymbols, error) {
+	l.loads++
+	for i := range l.snapshots {
+		if l.snapshots[i].SnapshotTime == snapshotTime.UnixNano()/int64(time.Millisecond) {
+			return &l.snapshots[i], nil
+		}
+	}
+	return nil, gocql.ErrNotFound
+}
+
+func ms(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func testCache(capacity int) (*CachingDBLoader, *testLoader, time.Time) {
+	now := time.Date(2019, 1, 3, 12, 0, 0, 0, time.UTC)
+	loader := &testLoader{snapshots: []types.ExchangeSymbols{
+		{ExchangeID: 1, SnapshotTime: ms(now.AddDate(0, 0, -2))},
+		{ExchangeID: 1, SnapshotTime: ms(now.AddDate(0, 0, -1))},
+		{ExchangeID: 1, SnapshotTime: ms(now.Add(-time.Hour))}}}
+	cache := NewCachingDBLoader(loader, capacity)
+	cache.now = func() time.Time { return now }
+	return cache, loader, now
+}
+
+func date(year, month, day int) func() (int, int, int, error) {
+	return func() (int, int, int, error) { return year, month, day, nil }
+}
+
+func TestCachingDBLoaderLatest(t *testing.T) {
+	cache, loader, now := testCache(10)
+
+	// Until the latest snapshot is known, the lookups of the current day go to the DB every time
+	for i := 0; i < 2; i++ {
+		r, err := cache.LoadSymbolsSnapshots([]int{1}, date(2019, 1, 3))
+		assert.NoError(t, err)
+		assert.Equal(t, ms(now.Add(-time.Hour)), r.Exchanges[0].SnapshotTime)
+	}
+	assert.Equal(t, 2, loader.loads)
+
+	cache.Update(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{loader.snapshots[2]}})
+	r, err := cache.LoadSymbolsSnapshots([]int{1}, date(2019, 1, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, ms(now.Add(-time.Hour)), r.Exchanges[0].SnapshotTime)
+	r, err = cache.LoadSymbolsSnapshotsAt([]int{1}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, ms(now.Add(-time.Hour)), r.Exchanges[0].SnapshotTime)
+	assert.Equal(t, 2, loader.loads)
+
+	// An older snapshot does not replace the latest one
+	cache.Update(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{loader.snapshots[0]}})
+	r, err = cache.LoadSymbolsSnapshotsAt([]int{1}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, ms(now.Add(-time.Hour)), r.Exchanges[0].SnapshotTime)
+
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 2, Entries: 0, Latest: 1}, cache.Stats())
+}
+
+func TestCachingDBLoaderHistory(t *testing.T) {
+	cache, loader, now := testCache(2)
+
+	for i := 0; i < 2; i++ {
+		r, err := cache.LoadSymbolsSnapshots([]int{1}, date(2019, 1, 2))
+		assert.NoError(t, err)
+		assert.Equal(t, ms(now.AddDate(0, 0, -1)), r.Exchanges[0].SnapshotTime)
+		r, err = cache.LoadSymbolsSnapshotsAt([]int{1}, now.Add(-2*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, ms(now.AddDate(0, 0, -1)), r.Exchanges[0].SnapshotTime)
+	}
+	assert.Equal(t, 2, loader.loads)
+
+	// The least recently used lookup is evicted
+	_, err := cache.LoadSnapshot(1, now.AddDate(0, 0, -2))
+	assert.NoError(t, err)
+	_, err = cache.LoadSymbolsSnapshotsAt([]int{1}, now.Add(-2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, loader.loads)
+	_, err = cache.LoadSymbolsSnapshots([]int{1}, date(2019, 1, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, loader.loads)
+
+	// The failed lookups are not cached
+	for i := 0; i < 2; i++ {
+		_, err = cache.LoadSnapshot(1, now)
+		assert.Equal(t, gocql.ErrNotFound, err)
+	}
+	assert.Equal(t, 6, loader.loads)
+	assert.Equal(t, 2, cache.Stats().Entries)
+}
@@ -0,0 +1,259 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+
+	"github.com/scylladb/gocqlx"
+	"github.com/scylladb/gocqlx/qb"
+
+	"github.com/etrubenok/make-trades-registry/fetchers"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// GetPreviousDate returns year, month, day of the previous day from the currentTime
+func GetPreviousDate(currentTime time.Time) (int, int, int) {
+	t := currentTime.AddDate(0, 0, -1).UnixNano() / int64(time.Millisecond)
+	year, month, day := fetchers.GetYearMonthDay(t)
+	glog.V(1).Infof("GetPreviousDate: previous day (year: %d, month: %d, day: %d)",
+		year, month, day)
+	return year, month, day
+}
+
+// GetYearMonthDayUTC returns year, month and day of the given time in UTC
+func GetYearMonthDayUTC(t time.Time) (int, int, int) {
+	t = t.UTC()
+	return t.Year(), int(t.Month()), t.Day()
+}
+
+// DBImporter is an interface for importing data into the database
+type DBImporter interface {
+	SaveSymbolsSnapshots(snapshot *types.ExchangesSymbols) error
+}
+
+// DBImporterImpl is an implementation of DBImporter interface
+type DBImporterImpl struct {
+	session *gocql.Session
+}
+
+// NewDBImporter instantiates object of DBImporter interface (DBImporterImpl class)
+func NewDBImporter(session *gocql.Session) DBImporter {
+	d := DBImporterImpl{
+		session: session}
+	return &d
+}
+
+// SaveSymbolsSnapshots saves symbols snapshots into the database
+func (d *DBImporterImpl) SaveSymbolsSnapshots(snapshots *types.ExchangesSymbols) error {
+	for _, e := range snapshots.Exchanges {
+		err := d.SaveSymbols(&e)
+		if err != nil {
+			glog.Errorf("SaveSymbolsSnapshots: cannot save symbols of exchange id '%d' into DB due to error %s", e.ExchangeID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveSymbols saves the symbols for one exchange
+func (d *DBImporterImpl) SaveSymbols(exchangeSymbols *types.ExchangeSymbols) error {
+	stmt, names := qb.Insert("maketrades2.symbols_snapshots").Columns("year",
+		"month",
+		"day",
+		"exchange_id",
+		"snapshot_time",
+		"symbols").ToCql()
+	query := gocqlx.Query(d.session.Query(stmt), names).BindStruct(exchangeSymbols)
+
+	if err := query.ExecRelease(); err != nil {
+		glog.Errorf("SaveSymbols: cannot insert symbols for exchange id '%d' into the DB due to error %s", exchangeSymbols.ExchangeID, err)
+		return err
+	}
+	return nil
+}
+
+// DBLoader is an interface for the DB reading operations
+type DBLoader interface {
+	LoadSymbolsSnapshots(exchangeIDs []int, getDate func() (int, int, int, error)) (*types.ExchangesSymbols, error)
+	LoadSymbolsSnapshotsAt(exchangeIDs []int, at time.Time) (*types.ExchangesSymbols, error)
+	ListSnapshots(exchangeID int, from, to time.Time, limit int) ([]types.ExchangeSymbols, error)
+	LoadSnapshot(exchangeID int, snapshotTime time.Time) (*types.ExchangeSymbols, error)
+}
+
+// DBLoaderImpl is an implementation of DBLoader interface
+type DBLoaderImpl struct {
+	session      *gocql.Session
+	lookBackDays int
+}
+
+// NewDBLoader instantiates object of DBLoader interface (DBLoaderImpl class).
+// lookBackDays is the number of day partitions before the requested one searched for a snapshot
+func NewDBLoader(session *gocql.Session, lookBackDays int) DBLoader {
+	l := DBLoaderImpl{
+		session:      session,
+		lookBackDays: lookBackDays}
+	return &l
+}
+
+// LoadSymbolsSnapshots loads the latest snapshots of symbols for the exchangeIDs on the date returned by getDate.
+// If an exchange has no snapshot on that date, the previous days are tried up to lookBackDays days back
+func (l *DBLoaderImpl) LoadSymbolsSnapshots(exchangeIDs []int, getDate func() (int, int, int, error)) (*types.ExchangesSymbols, error) {
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0),
+	}
+	year, month, day, err := getDate()
+	if err != nil {
+		glog.Errorf("LoadSymbolsSnapshots: cannot get year, month and day due to error '%s'", err)
+		return nil, err
+	}
+	glog.V(1).Infof("LoadSymbolsSnapshots.FetchSymbols: year: %d, month: %d, day: %d", year, month, day)
+	requestedDate := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	for _, e := range exchangeIDs {
+		exchangeID := e
+		symbols, err := walkBackDays(requestedDate, l.lookBackDays, func(year, month, day int) (*types.ExchangeSymbols, error) {
+			return l.LoadSymbols(year, month, day, exchangeID)
+		})
+		if err == gocql.ErrNotFound {
+			glog.Errorf("LoadSymbolsSnapshots: no symbols of exchange id '%d' in DB within %d days before year: %d, month: %d, day: %d",
+				e, l.lookBackDays, year, month, day)
+			return nil, err
+		}
+		if err != nil {
+			glog.Errorf("LoadSymbolsSnapshots: cannot load symbols of exchange id '%d' from DB due to error %s", e, err)
+			return nil, err
+		}
+		r.Exchanges = append(r.Exchanges, *symbols)
+	}
+	return &r, nil
+}
+
+// LoadSymbols loads the latest snapshot of symbols for a given exchnage from DB
+func (l *DBLoaderImpl) LoadSymbols(year, month, day, exchangeID int) (*types.ExchangeSymbols, error) {
+	var symbols types.ExchangeSymbols
+	stmt, names := qb.Select("maketrades2.symbols_snapshots").Where(qb.Eq("year"), qb.Eq("month"), qb.Eq("day"), qb.Eq("exchange_id")).OrderBy("snapshot_time", qb.DESC).Limit(1).ToCql()
+	q := gocqlx.Query(l.session.Query(stmt), names).BindMap(qb.M{
+		"year": year, "month": month, "day": day, "exchange_id": exchangeID,
+	})
+	if err := q.GetRelease(&symbols); err != nil {
+		if err != gocql.ErrNotFound {
+			glog.Errorf("LoadSymbols: cannot load the last snapshots with symbols for exchnage id '%d' due to error %s",
+				exchangeID,
+				err)
+		}
+		return nil, err
+	}
+	return &symbols, nil
+}
+
+// LoadSymbolsSnapshotsAt loads for every exchange the last snapshot of symbols taken at or before the given time.
+// The day partitions are walked back from the day of the given time up to lookBackDays days
+func (l *DBLoaderImpl) LoadSymbolsSnapshotsAt(exchangeIDs []int, at time.Time) (*types.ExchangesSymbols, error) {
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0),
+	}
+	for _, e := range exchangeIDs {
+		exchangeID := e
+		symbols, err := walkBackDays(at, l.lookBackDays, func(year, month, day int) (*types.ExchangeSymbols, error) {
+			return l.LoadSymbolsAt(year, month, day, exchangeID, at)
+		})
+		if err != nil {
+			glog.Errorf("LoadSymbolsSnapshotsAt: cannot load symbols of exchange id '%d' at %s from DB due to error %s", e, at, err)
+			return nil, err
+		}
+		r.Exchanges = append(r.Exchanges, *symbols)
+	}
+	return &r, nil
+}
+
+// walkBackDays loads the snapshot with load from the day partition of at and, while there is none, from the previous
+// ones up to lookBackDays days back. A negative lookBackDays searches the day of at only. It returns gocql.ErrNotFound
+// if none of the days has a snapshot
+func walkBackDays(at time.Time, lookBackDays int, load func(year, month, day int) (*types.ExchangeSymbols, error)) (*types.ExchangeSymbols, error) {
+	if lookBackDays < 0 {
+		glog.Warningf("walkBackDays: negative number of look back days %d, only the day of %s is searched", lookBackDays, at)
+		lookBackDays = 0
+	}
+	for d := 0; d <= lookBackDays; d++ {
+		year, month, day := GetYearMonthDayUTC(at.AddDate(0, 0, -d))
+		symbols, err := load(year, month, day)
+		if err != gocql.ErrNotFound {
+			return symbols, err
+		}
+		glog.V(1).Infof("walkBackDays: no snapshot on year: %d, month: %d, day: %d", year, month, day)
+	}
+	return nil, gocql.ErrNotFound
+}
+
+// LoadSymbolsAt loads the last snapshot of symbols taken at or before the given time within one day partition
+func (l *DBLoaderImpl) LoadSymbolsAt(year, month, day, exchangeID int, at time.Time) (*types.ExchangeSymbols, error) {
+	var symbols types.ExchangeSymbols
+	stmt, names := qb.Select("maketrades2.symbols_snapshots").Where(qb.Eq("year"), qb.Eq("month"), qb.Eq("day"), qb.Eq("exchange_id"), qb.LtOrEq("snapshot_time")).OrderBy("snapshot_time", qb.DESC).Limit(1).ToCql()
+	q := gocqlx.Query(l.session.Query(stmt), names).BindMap(qb.M{
+		"year": year, "month": month, "day": day, "exchange_id": exchangeID, "snapshot_time": at,
+	})
+	if err := q.GetRelease(&symbols); err != nil {
+		if err != gocql.ErrNotFound {
+			glog.Errorf("LoadSymbolsAt: cannot load the snapshot with symbols for exchnage id '%d' at %s due to error %s",
+				exchangeID,
+				at,
+				err)
+		}
+		return nil, err
+	}
+	return &symbols, nil
+}
+
+// MaxListDays is the maximum number of days before to ListSnapshots walks the day partitions for
+const MaxListDays = 31
+
+// ListSnapshots loads up to limit snapshots of the exchange taken between from and to (both inclusive),
+// the latest first. The day partitions are walked back from the day of to until the day of from
+// but no further than MaxListDays days
+func (l *DBLoaderImpl) ListSnapshots(exchangeID int, from, to time.Time, limit int) ([]types.ExchangeSymbols, error) {
+	if earliest := to.AddDate(0, 0, -MaxListDays); from.Before(earliest) {
+		glog.Warningf("ListSnapshots: limiting the snapshots of exchange id '%d' to the ones taken after %s", exchangeID, earliest)
+		from = earliest
+	}
+	r := make([]types.ExchangeSymbols, 0)
+	fromYear, fromMonth, fromDay := GetYearMonthDayUTC(from)
+	firstDay := time.Date(fromYear, time.Month(fromMonth), fromDay, 0, 0, 0, 0, time.UTC)
+	for day := to.UTC(); !day.Before(firstDay) && len(r) < limit; day = day.AddDate(0, 0, -1) {
+		year, month, d := GetYearMonthDayUTC(day)
+		var snapshots []types.ExchangeSymbols
+		stmt, names := qb.Select("maketrades2.symbols_snapshots").Where(qb.Eq("year"), qb.Eq("month"), qb.Eq("day"), qb.Eq("exchange_id"),
+			qb.GtOrEqNamed("snapshot_time", "from"), qb.LtOrEqNamed("snapshot_time", "to")).OrderBy("snapshot_time", qb.DESC).Limit(uint(limit - len(r))).ToCql()
+		q := gocqlx.Query(l.session.Query(stmt), names).BindMap(qb.M{
+			"year": year, "month": month, "day": d, "exchange_id": exchangeID, "from": from, "to": to,
+		})
+		if err := q.SelectRelease(&snapshots); err != nil {
+			glog.Errorf("ListSnapshots: cannot load the snapshots of exchange id '%d' for year: %d, month: %d, day: %d due to error %s",
+				exchangeID, year, month, d, err)
+			return nil, err
+		}
+		r = append(r, snapshots...)
+	}
+	return r, nil
+}
+
+// LoadSnapshot loads the snapshot of the exchange taken exactly at snapshotTime
+func (l *DBLoaderImpl) LoadSnapshot(exchangeID int, snapshotTime time.Time) (*types.ExchangeSymbols, error) {
+	var symbols types.ExchangeSymbols
+	year, month, day := GetYearMonthDayUTC(snapshotTime)
+	stmt, names := qb.Select("maketrades2.symbols_snapshots").Where(qb.Eq("year"), qb.Eq("month"), qb.Eq("day"), qb.Eq("exchange_id"), qb.Eq("snapshot_time")).ToCql()
+	q := gocqlx.Query(l.session.Query(stmt), names).BindMap(qb.M{
+		"year": year, "month": month, "day": day, "exchange_id": exchangeID, "snapshot_time": snapshotTime,
+	})
+	if err := q.GetRelease(&symbols); err != nil {
+		if err != gocql.ErrNotFound {
+			glog.Errorf("LoadSnapshot: cannot load the snapshot of exchnage id '%d' at %s due to error %s",
+				exchangeID,
+				snapshotTime,
+				err)
+		}
+		return nil, err
+	}
+	return &symbols, nil
+}
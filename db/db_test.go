@@ -0,0 +1,96 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestGetPreviousDate(t *testing.T) {
+	current := time.Date(int(2019), time.Month(1), int(10), int(0), int(0), int(0), int(0), time.UTC)
+
+	year, month, day := GetPreviousDate(current)
+	assert.Equal(t, 2019, year)
+	assert.Equal(t, 1, month)
+	assert.Equal(t, 9, day)
+}
+
+func TestGetPreviousDatePrev(t *testing.T) {
+	current := time.Date(int(2019), time.Month(1), int(10), int(0), int(23), int(1), int(0), time.UTC)
+
+	year, month, day := GetPreviousDate(current)
+	assert.Equal(t, 2019, year)
+	assert.Equal(t, 1, month)
+	assert.Equal(t, 9, day)
+}
+
+func TestGetPreviousDatePrevMonth(t *testing.T) {
+	current := time.Date(int(2019), time.Month(3), int(1), int(0), int(23), int(1), int(0), time.UTC)
+
+	year, month, day := GetPreviousDate(current)
+	assert.Equal(t, 2019, year)
+	assert.Equal(t, 2, month)
+	assert.Equal(t, 28, day)
+}
+
+func TestGetPreviousDatePrevYear(t *testing.T) {
+	current := time.Date(int(2020), time.Month(1), int(1), int(0), int(23), int(1), int(0), time.UTC)
+
+	year, month, day := GetPreviousDate(current)
+	assert.Equal(t, 2019, year)
+	assert.Equal(t, 12, month)
+	assert.Equal(t, 31, day)
+}
+
+func TestGetYearMonthDayUTC(t *testing.T) {
+	current := time.Date(int(2019), time.Month(3), int(4), int(23), int(5), int(0), int(0), time.FixedZone("UTC-5", -5*60*60))
+
+	year, month, day := GetYearMonthDayUTC(current)
+	assert.Equal(t, 2019, year)
+	assert.Equal(t, 3, month)
+	assert.Equal(t, 5, day)
+}
+
+func TestWalkBackDays(t *testing.T) {
+	at := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	loaded := make([]string, 0)
+	symbols, err := walkBackDays(at, 7, func(year, month, day int) (*types.ExchangeSymbols, error) {
+		loaded = append(loaded, fmt.Sprintf("%d-%d-%d", year, month, day))
+		if day == 27 {
+			return &types.ExchangeSymbols{Year: year, Month: month, Day: day}, nil
+		}
+		return nil, gocql.ErrNotFound
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 27, symbols.Day)
+	assert.Equal(t, []string{"2019-3-1", "2019-2-28", "2019-2-27"}, loaded)
+}
+
+func TestWalkBackDaysNotFound(t *testing.T) {
+	calls := 0
+	_, err := walkBackDays(time.Now(), 2, func(year, month, day int) (*types.ExchangeSymbols, error) {
+		calls++
+		return nil, gocql.ErrNotFound
+	})
+	assert.Equal(t, gocql.ErrNotFound, err)
+	assert.Equal(t, 3, calls)
+
+	_, err = walkBackDays(time.Now(), 0, func(year, month, day int) (*types.ExchangeSymbols, error) {
+		return nil, errors.New("timeout")
+	})
+	assert.EqualError(t, err, "timeout")
+
+	calls = 0
+	_, err = walkBackDays(time.Now(), -1, func(year, month, day int) (*types.ExchangeSymbols, error) {
+		calls++
+		return nil, gocql.ErrNotFound
+	})
+	assert.Equal(t, gocql.ErrNotFound, err)
+	assert.Equal(t, 1, calls)
+}
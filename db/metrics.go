@@ -0,0 +1,82 @@
+package db
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/etrubenok/make-trades-registry/metrics"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// MeteredDBImporter is an implementation of DBImporter interface recording the metrics of the writes of another DBImporter
+type MeteredDBImporter struct {
+	importer DBImporter
+}
+
+// NewMeteredDBImporter instantiates object of DBImporter interface (MeteredDBImporter class)
+func NewMeteredDBImporter(importer DBImporter) DBImporter {
+	i := MeteredDBImporter{
+		importer: importer}
+	return &i
+}
+
+// SaveSymbolsSnapshots saves symbols snapshots into the database
+func (i *MeteredDBImporter) SaveSymbolsSnapshots(snapshots *types.ExchangesSymbols) error {
+	start := time.Now()
+	err := i.importer.SaveSymbolsSnapshots(snapshots)
+	metrics.ObserveDB("save_snapshots", start, err)
+	return err
+}
+
+// MeteredDBLoader is an implementation of DBLoader interface recording the metrics of the reads of another DBLoader.
+// Missing snapshots are not counted as errors
+type MeteredDBLoader struct {
+	loader DBLoader
+}
+
+// NewMeteredDBLoader instantiates object of DBLoader interface (MeteredDBLoader class)
+func NewMeteredDBLoader(loader DBLoader) DBLoader {
+	l := MeteredDBLoader{
+		loader: loader}
+	return &l
+}
+
+func observeRead(operation string, start time.Time, err error) {
+	if err == gocql.ErrNotFound {
+		err = nil
+	}
+	metrics.ObserveDB(operation, start, err)
+}
+
+// LoadSymbolsSnapshots loads the latest snapshots of symbols for the exchangeIDs on the date returned by getDate
+func (l *MeteredDBLoader) LoadSymbolsSnapshots(exchangeIDs []int, getDate func() (int, int, int, error)) (*types.ExchangesSymbols, error) {
+	start := time.Now()
+	r, err := l.loader.LoadSymbolsSnapshots(exchangeIDs, getDate)
+	observeRead("load_snapshots", start, err)
+	return r, err
+}
+
+// LoadSymbolsSnapshotsAt loads for every exchange the last snapshot of symbols taken at or before the given time
+func (l *MeteredDBLoader) LoadSymbolsSnapshotsAt(exchangeIDs []int, at time.Time) (*types.ExchangesSymbols, error) {
+	start := time.Now()
+	r, err := l.loader.LoadSymbolsSnapshotsAt(exchangeIDs, at)
+	observeRead("load_snapshots_at", start, err)
+	return r, err
+}
+
+// ListSnapshots loads up to limit snapshots of the exchange taken between from and to
+func (l *MeteredDBLoader) ListSnapshots(exchangeID int, from, to time.Time, limit int) ([]types.ExchangeSymbols, error) {
+	start := time.Now()
+	r, err := l.loader.ListSnapshots(exchangeID, from, to, limit)
+	observeRead("list_snapshots", start, err)
+	return r, err
+}
+
+// LoadSnapshot loads the snapshot of the exchange taken exactly at snapshotTime
+func (l *MeteredDBLoader) LoadSnapshot(exchangeID int, snapshotTime time.Time) (*types.ExchangeSymbols, error) {
+	start := time.Now()
+	r, err := l.loader.LoadSnapshot(exchangeID, snapshotTime)
+	observeRead("load_snapshot", start, err)
+	return r, err
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/changes"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// ParsePointInTime parses a point in time given as a date in format 'yyyy-mm-dd', which means the end of that day in UTC,
+// as a time in RFC3339 format or as the number of milliseconds since epoch
+func ParsePointInTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Millisecond), nil
+	}
+	return ParseTime(value)
+}
+
+func getSymbolsDiff(c *gin.Context) {
+	exchange := c.Request.URL.Query().Get("exchange")
+	exchangeIDs, err := GetExchangeIDs([]string{exchange})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	from, err := ParsePointInTime(c.Request.URL.Query().Get("from"))
+	if err != nil {
+		glog.Errorf("getSymbolsDiff: cannot parse 'from' due to error %s", err)
+		respondError(c, NewInvalidParameterError("from", "'from' must be a date, a time in RFC3339 format or milliseconds since epoch"))
+		return
+	}
+	to := time.Now().UTC()
+	if value := c.Request.URL.Query().Get("to"); value != "" {
+		to, err = ParsePointInTime(value)
+		if err != nil {
+			glog.Errorf("getSymbolsDiff: cannot parse 'to' due to error %s", err)
+			respondError(c, NewInvalidParameterError("to", "'to' must be a date, a time in RFC3339 format or milliseconds since epoch"))
+			return
+		}
+	}
+	if from.After(to) {
+		respondError(c, NewInvalidParameterError("from", "'from' must not be after 'to'"))
+		return
+	}
+
+	l := snapshotsCache
+	before, err := l.LoadSymbolsSnapshotsAt(exchangeIDs, from)
+	if err != nil {
+		glog.Errorf("getSymbolsDiff: cannot load the snapshot of exchange '%s' at %s due to error %s", exchange, from, err)
+		respondError(c, NewStorageError(err))
+		return
+	}
+	after, err := l.LoadSymbolsSnapshotsAt(exchangeIDs, to)
+	if err != nil {
+		glog.Errorf("getSymbolsDiff: cannot load the snapshot of exchange '%s' at %s due to error %s", exchange, to, err)
+		respondError(c, NewStorageError(err))
+		return
+	}
+
+	d := changes.Diff(&before.Exchanges[0], &after.Exchanges[0])
+	if c.Request.URL.Query().Get("format") == "text" {
+		c.String(http.StatusOK, changes.FormatText(exchange, d))
+		return
+	}
+	c.JSON(http.StatusOK, types.APISymbolsDiff{
+		Exchange:    exchange,
+		SymbolsDiff: d})
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePointInTimeDate(t *testing.T) {
+	parsed, err := ParsePointInTime("2019-03-04")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, time.March, 4, 23, 59, 59, 999000000, time.UTC), parsed)
+}
+
+func TestGetSymbolsDiffFromAfterTo(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/symbols/diff?exchange=binance&from=2019-03-05&to=2019-03-04", nil)
+	getSymbolsDiff(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// HTTPError is an error carrying the HTTP status and the API error returned to the client
+type HTTPError struct {
+	Status   int
+	APIError types.APIError
+}
+
+// Error returns the message of the error
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.APIError.Code, e.APIError.Message)
+}
+
+// NewInvalidParameterError creates an error for a malformed request parameter
+func NewInvalidParameterError(parameter string, message string) *HTTPError {
+	return &HTTPError{
+		Status: http.StatusBadRequest,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeInvalidParameter,
+			Message: message,
+			Details: gin.H{"parameter": parameter}}}
+}
+
+// NewUnknownExchangeError creates an error for a not supported exchange
+func NewUnknownExchangeError(exchange string) *HTTPError {
+	return &HTTPError{
+		Status: http.StatusBadRequest,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeUnknownExchange,
+			Message: fmt.Sprintf("exchange '%s' is not supported", exchange),
+			Details: gin.H{"supported": GetAllExchanges()}}}
+}
+
+// NewNotFoundError creates an error for missing data
+func NewNotFoundError(message string, details interface{}) *HTTPError {
+	return &HTTPError{
+		Status: http.StatusNotFound,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeNotFound,
+			Message: message,
+			Details: details}}
+}
+
+// NewStorageError creates an error for a failure of the backing store. Missing rows are reported as not found
+func NewStorageError(err error) *HTTPError {
+	if err == gocql.ErrNotFound {
+		return NewNotFoundError("snapshot not found", nil)
+	}
+	return &HTTPError{
+		Status: http.StatusServiceUnavailable,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeStorageUnavailable,
+			Message: "the symbols storage is unavailable"}}
+}
+
+// NewUnauthorizedError creates an error for a request without valid credentials
+func NewUnauthorizedError() *HTTPError {
+	return &HTTPError{
+		Status: http.StatusUnauthorized,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeUnauthorized,
+			Message: "valid credentials are required"}}
+}
+
+// NewConflictError creates an error for a request conflicting with the current state
+func NewConflictError(message string, details interface{}) *HTTPError {
+	return &HTTPError{
+		Status: http.StatusConflict,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeConflict,
+			Message: message,
+			Details: details}}
+}
+
+// NewNotAcceptableError creates an error for a request asking for an unsupported response format
+func NewNotAcceptableError(format string) *HTTPError {
+	return &HTTPError{
+		Status: http.StatusNotAcceptable,
+		APIError: types.APIError{
+			Code:    types.ErrorCodeNotAcceptable,
+			Message: fmt.Sprintf("format '%s' is not supported", format),
+			Details: gin.H{"supported": []string{"json", "ndjson", "csv", "msgpack", "protobuf"}}}}
+}
+
+// respondError writes the error response. The errors which are not HTTPError are reported as internal errors
+func respondError(c *gin.Context, err error) {
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		glog.Errorf("respondError: internal error on %s %s: %s", c.Request.Method, c.Request.URL.Path, err)
+		httpErr = &HTTPError{
+			Status: http.StatusInternalServerError,
+			APIError: types.APIError{
+				Code:    types.ErrorCodeInternal,
+				Message: "internal server error"}}
+	}
+	c.JSON(httpErr.Status, types.APIErrorResponse{Error: httpErr.APIError})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestNewStorageError(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, NewStorageError(gocql.ErrNotFound).Status)
+	assert.Equal(t, types.ErrorCodeNotFound, NewStorageError(gocql.ErrNotFound).APIError.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, NewStorageError(gocql.ErrNoConnections).Status)
+	assert.Equal(t, types.ErrorCodeStorageUnavailable, NewStorageError(gocql.ErrNoConnections).APIError.Code)
+}
+
+func TestRespondError(t *testing.T) {
+	c, w := testContext("/symbols")
+	respondError(c, NewUnknownExchangeError("unknown"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var r types.APIErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &r))
+	assert.Equal(t, types.ErrorCodeUnknownExchange, r.Error.Code)
+	assert.Equal(t, map[string]interface{}{"supported": []interface{}{"binance", "bitfinex"}}, r.Error.Details)
+}
+
+func TestRespondErrorInternal(t *testing.T) {
+	c, w := testContext("/symbols")
+	respondError(c, errors.New("something went wrong"))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var r types.APIErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &r))
+	assert.Equal(t, types.ErrorCodeInternal, r.Error.Code)
+}
+
+func TestParseSnapshotQueryInvalidDate(t *testing.T) {
+	c, _ := testContext("/symbols?date=2019-13-45")
+	_, err := ParseSnapshotQuery(c)
+	httpErr, ok := err.(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+	assert.Equal(t, gin.H{"parameter": "date"}, httpErr.APIError.Details)
+}
+
+func TestParseSnapshotQueryDate(t *testing.T) {
+	c, _ := testContext("/symbols?date=2019-03-04")
+	q, err := ParseSnapshotQuery(c)
+	assert.NoError(t, err)
+	assert.Equal(t, &SnapshotQuery{Year: 2019, Month: 3, Day: 4}, q)
+}
+
+func TestGetExchangeIDsUnknown(t *testing.T) {
+	_, err := GetExchangeIDs([]string{"binance", "unknown"})
+	httpErr, ok := err.(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+	assert.Equal(t, types.ErrorCodeUnknownExchange, httpErr.APIError.Code)
+}
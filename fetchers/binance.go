@@ -4,13 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/etrubenok/make-trades-registry/archive"
+	"github.com/etrubenok/make-trades-registry/assets"
 	"github.com/etrubenok/make-trades-registry/types"
-	"github.com/etrubenok/make-trades-types/registry"
 	"github.com/golang/glog"
 )
 
@@ -24,37 +23,35 @@ func NewBinanceFetcher() Fetcher {
 	return &f
 }
 
-// FetchSymbols fetches symbols from Binance
+// binanceExchangeInfoURL is the endpoint of the Binance symbols
+const binanceExchangeInfoURL = "https://api.binance.com/api/v1/exchangeInfo"
+
+// FetchSymbols fetches symbols from Binance and archives the raw response
 func (f *BinanceFetcher) FetchSymbols() (*types.ExchangeSymbols, error) {
-	raw, err := f.GetBinanceExchangeInfo("https://api.binance.com/api/v1/exchangeInfo")
+	fetchedAt := time.Now()
+	raw, err := FetchRaw(binanceExchangeInfoURL)
 	if err != nil {
 		glog.Errorf("BinanceFetcher.FetchSymbols: cannot fetch symbols due to error %s", err)
 		return nil, err
 	}
-	_, symbols, err := f.GetListOfSymbolsAndTime(raw)
+	archive.Record("binance", binanceExchangeInfoURL, fetchedAt, raw)
+	return f.ParseSymbols(raw, fetchedAt)
+}
+
+// ParseSymbols converts the raw exchange information response of Binance into the snapshot taken at fetchedAt
+func (f *BinanceFetcher) ParseSymbols(raw []byte, fetchedAt time.Time) (*types.ExchangeSymbols, error) {
+	response, err := ConvertToJSON(string(raw))
 	if err != nil {
-		glog.Errorf("BinanceFetcher.FetchSymbols: cannot get symbols due to error %s", err)
+		glog.Errorf("BinanceFetcher.ParseSymbols: cannot get the response as JSON due to error %s", err)
 		return nil, err
 	}
-	exchangeID, err := registry.GetExchangeID("binance")
+	_, symbols, err := f.GetListOfSymbolsAndTime(response)
 	if err != nil {
-		glog.Errorf("BinanceFetcher.FetchSymbols: cannot get exchangeID for 'binance' due to error %s", err)
+		glog.Errorf("BinanceFetcher.ParseSymbols: cannot get symbols due to error %s", err)
 		return nil, err
 	}
-	r := types.ExchangeSymbols{
-		ExchangeID:   exchangeID,
-		SnapshotTime: time.Now().UnixNano() / int64(time.Millisecond),
-		Symbols:      symbols,
-	}
-
-	year, month, day := GetYearMonthDay(r.SnapshotTime)
-	glog.V(1).Infof("BinanceFetcher.FetchSymbols: year: %d, month: %d, day: %d", year, month, day)
-
-	r.Year = year
-	r.Month = month
-	r.Day = day
-
-	return &r, nil
+	assets.Default.NormaliseSymbols("binance", symbols)
+	return NewExchangeSymbols("binance", fetchedAt, symbols)
 }
 
 // ConvertToJSON converts string to JSON struct
@@ -74,17 +71,11 @@ func ConvertToJSON(message string) (map[string]interface{}, error) {
 
 // GetBinanceExchangeInfo requests the exchange information from Binance
 func (f *BinanceFetcher) GetBinanceExchangeInfo(url string) (map[string]interface{}, error) {
-	resp, err := http.Get(url)
+	body, err := FetchRaw(url)
 	if err != nil {
 		glog.Errorf("GetBinanceExchangeInfo: cannot get the exchange information from Binance due to error %s", err)
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		glog.Errorf("GetBinanceExchangeInfo: cannot get the exchange information response from Binance due to error %s", err)
-		return nil, err
-	}
 	response, err := ConvertToJSON(string(body))
 	if err != nil {
 		glog.Errorf("GetBinanceExchangeInfo: cannot get the response %s as JSON due to error  %s", body, err)
@@ -109,7 +100,8 @@ func (f *BinanceFetcher) GetListOfSymbolsAndTime(response map[string]interface{}
 	}
 	symbols := make([]types.SymbolInfo, 0)
 	for _, s := range rawSymbols {
-		symbol := types.SymbolInfo{}
+		symbol := types.SymbolInfo{
+			Type: types.InstrumentTypeSpot}
 		sMapped := s.(map[string]interface{})
 		symbol.Symbol, ok = sMapped["symbol"].(string)
 		if !ok {
@@ -153,7 +145,43 @@ func (f *BinanceFetcher) GetListOfSymbolsAndTime(response map[string]interface{}
 		if !ok {
 			glog.Errorf("GetListOfSymbolsAndTime cannot extract 'icebergAllowed' from the symbol JSON %v", sMapped)
 		}
+
+		filters, ok := sMapped["filters"].([]interface{})
+		if !ok {
+			glog.Errorf("GetListOfSymbolsAndTime cannot extract 'filters' from the symbol JSON %v", sMapped)
+		}
+		for _, f := range filters {
+			filter, err := ConvertFilter(f)
+			if err != nil {
+				glog.Errorf("GetListOfSymbolsAndTime cannot convert filter of symbol '%s' due to error %s", symbol.Symbol, err)
+				continue
+			}
+			symbol.Filters = append(symbol.Filters, *filter)
+		}
 		symbols = append(symbols, symbol)
 	}
 	return time.Unix(0, serverTime*int64(time.Millisecond)), symbols, nil
 }
+
+// ConvertFilter converts a Binance symbol filter into the make trades symbol filter.
+// All the fields of the filter except 'filterType' become the filter params
+func ConvertFilter(rawFilter interface{}) (*types.SymbolFilter, error) {
+	fMapped, ok := rawFilter.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ConvertFilter: filter %v is not a JSON object", rawFilter)
+	}
+	filterType, ok := fMapped["filterType"].(string)
+	if !ok {
+		return nil, fmt.Errorf("ConvertFilter: cannot extract 'filterType' from the filter JSON %v", fMapped)
+	}
+	filter := types.SymbolFilter{
+		FilterType: filterType,
+		Params:     make(map[string]string)}
+	for k, v := range fMapped {
+		if k == "filterType" {
+			continue
+		}
+		filter.Params[k] = fmt.Sprint(v)
+	}
+	return &filter, nil
+}
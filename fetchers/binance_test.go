@@ -19,3 +19,27 @@ func TestFetchSymbols(t *testing.T) {
 	}
 	assert.Contains(t, names, strings.ToUpper("btcusdt"))
 }
+
+func TestConvertFilter(t *testing.T) {
+	m, err := ConvertToJSON(`{"filterType": "PRICE_FILTER", "minPrice": "0.00000100", "maxPrice": "100000.00000000", "tickSize": "0.00000100"}`)
+	assert.NoError(t, err)
+
+	filter, err := ConvertFilter(map[string]interface{}(m))
+	assert.NoError(t, err)
+	assert.Equal(t, "PRICE_FILTER", filter.FilterType)
+	assert.Equal(t, map[string]string{"minPrice": "0.00000100", "maxPrice": "100000.00000000", "tickSize": "0.00000100"}, filter.Params)
+}
+
+func TestConvertFilterNumbers(t *testing.T) {
+	m, err := ConvertToJSON(`{"filterType": "MAX_NUM_ALGO_ORDERS", "maxNumAlgoOrders": 5}`)
+	assert.NoError(t, err)
+
+	filter, err := ConvertFilter(map[string]interface{}(m))
+	assert.NoError(t, err)
+	assert.Equal(t, "5", filter.Params["maxNumAlgoOrders"])
+}
+
+func TestConvertFilterWithoutType(t *testing.T) {
+	_, err := ConvertFilter(map[string]interface{}{"minQty": "1"})
+	assert.Error(t, err)
+}
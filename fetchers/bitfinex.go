@@ -1,13 +1,16 @@
 package fetchers
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	bitfinex "github.com/bitfinexcom/bitfinex-api-go/v1"
+	"github.com/etrubenok/make-trades-registry/archive"
+	"github.com/etrubenok/make-trades-registry/assets"
 	"github.com/etrubenok/make-trades-registry/types"
-	"github.com/etrubenok/make-trades-types/registry"
 	"github.com/golang/glog"
 )
 
@@ -21,34 +24,36 @@ func NewBitfinexFetcher() Fetcher {
 	return &f
 }
 
-// FetchSymbols fetches symbols from Bitfinex
-func (f *BitfinexFetcher) FetchSymbols() (*types.ExchangeSymbols, error) {
-	pairs, err := bitfinex.NewClient().Pairs.AllDetailed()
+// bitfinexSymbolsDetailsURL is the endpoint of the Bitfinex pairs, the same one the Bitfinex client reads the detailed pairs from
+const bitfinexSymbolsDetailsURL = "https://api.bitfinex.com/v1/symbols_details"
 
-	symbols, err := f.ConvertSymbols(pairs)
+// FetchSymbols fetches symbols from Bitfinex and archives the raw response
+func (f *BitfinexFetcher) FetchSymbols() (*types.ExchangeSymbols, error) {
+	fetchedAt := time.Now()
+	raw, err := FetchRaw(bitfinexSymbolsDetailsURL)
 	if err != nil {
-		glog.Errorf("BitfinexFetcher.FetchSymbols: cannot convert symbols due to error %s", err)
+		glog.Errorf("BitfinexFetcher.FetchSymbols: cannot get the pairs due to error %s", err)
 		return nil, err
 	}
-	exchangeID, err := registry.GetExchangeID("bitfinex")
-	if err != nil {
-		glog.Errorf("BitfinexFetcher.FetchSymbols: cannot get exchangeID for 'bitfinex' due to error %s", err)
+	archive.Record("bitfinex", bitfinexSymbolsDetailsURL, fetchedAt, raw)
+	return f.ParseSymbols(raw, fetchedAt)
+}
+
+// ParseSymbols converts the raw detailed pairs response of Bitfinex into the snapshot taken at fetchedAt
+func (f *BitfinexFetcher) ParseSymbols(raw []byte, fetchedAt time.Time) (*types.ExchangeSymbols, error) {
+	var pairs []bitfinex.Pair
+	if err := json.Unmarshal(raw, &pairs); err != nil {
+		glog.Errorf("BitfinexFetcher.ParseSymbols: cannot parse the pairs due to error %s", err)
 		return nil, err
 	}
-	r := types.ExchangeSymbols{
-		ExchangeID:   exchangeID,
-		SnapshotTime: time.Now().UnixNano() / int64(time.Millisecond),
-		Symbols:      symbols,
-	}
-
-	year, month, day := GetYearMonthDay(r.SnapshotTime)
-	glog.V(1).Infof("BitfinexFetcher.FetchSymbols: year: %d, month: %d, day: %d", year, month, day)
-
-	r.Year = year
-	r.Month = month
-	r.Day = day
 
-	return &r, nil
+	symbols, err := f.ConvertSymbols(pairs)
+	if err != nil {
+		glog.Errorf("BitfinexFetcher.ParseSymbols: cannot convert symbols due to error %s", err)
+		return nil, err
+	}
+	assets.Default.NormaliseSymbols("bitfinex", symbols)
+	return NewExchangeSymbols("bitfinex", fetchedAt, symbols)
 }
 
 // ConvertSymbols converts Bitfinex pairs into the make trades symbols
@@ -56,27 +61,49 @@ func (f *BitfinexFetcher) ConvertSymbols(pairs []bitfinex.Pair) ([]types.SymbolI
 	symbols := make([]types.SymbolInfo, 0)
 	fPairs := make(map[string]bool)
 	for _, p := range pairs {
+		base, quote := SplitPair(p.Pair)
 		s := types.SymbolInfo{
 			Symbol:             fmt.Sprintf("t%s", strings.ToUpper(p.Pair)),
+			Status:             types.StatusTrading,
+			Type:               types.InstrumentTypeSpot,
+			BaseAsset:          base,
+			QuoteAsset:         quote,
 			BaseAssetPrecision: int64(p.PricePrecision),
-			QuotePrecision:     int64(p.PricePrecision)}
+			QuotePrecision:     int64(p.PricePrecision),
+			Filters: []types.SymbolFilter{{
+				FilterType: "LOT_SIZE",
+				Params: map[string]string{
+					"minQty": strconv.FormatFloat(p.MinimumOrderSize, 'f', -1, 64),
+					"maxQty": strconv.FormatFloat(p.MaximumOrderSize, 'f', -1, 64)}}}}
 		symbols = append(symbols, s)
 
 		if p.Margin {
-			// ASSUMPTION: pair name len is 6 and format is [one][two]
-			pair1 := fmt.Sprintf("f%s", strings.ToUpper(string([]byte(p.Pair)[3:])))
-			fPairs[pair1] = true
-
-			pair2 := fmt.Sprintf("f%s", strings.ToUpper(string([]byte(p.Pair)[:3])))
-			fPairs[pair2] = true
+			fPairs[base] = true
+			fPairs[quote] = true
 		}
 	}
 	for k := range fPairs {
 		s := types.SymbolInfo{
-			Symbol:             k,
+			Symbol:             fmt.Sprintf("f%s", k),
+			Status:             types.StatusTrading,
+			Type:               types.InstrumentTypeFunding,
+			BaseAsset:          k,
 			BaseAssetPrecision: int64(8),
 			QuotePrecision:     int64(8)}
 		symbols = append(symbols, s)
 	}
 	return symbols, nil
 }
+
+// SplitPair splits the Bitfinex pair name into the upper case base and quote assets.
+// The pair names are either in format [one][two] with 3 letters assets or [one]:[two] for the longer assets
+func SplitPair(pair string) (string, string) {
+	pair = strings.ToUpper(pair)
+	if i := strings.Index(pair, ":"); i >= 0 {
+		return pair[:i], pair[i+1:]
+	}
+	if len(pair) < 6 {
+		return pair, ""
+	}
+	return pair[:3], pair[3:]
+}
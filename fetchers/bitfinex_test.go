@@ -2,6 +2,10 @@ package fetchers
 
 import (
 	"testing"
+	"time"
+
+	bitfinex "github.com/bitfinexcom/bitfinex-api-go/v1"
+	"github.com/etrubenok/make-trades-registry/types"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -13,3 +17,49 @@ func TestBitfinexFetchSymbols(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestSplitPair(t *testing.T) {
+	base, quote := SplitPair("btcusd")
+	assert.Equal(t, "BTC", base)
+	assert.Equal(t, "USD", quote)
+
+	base, quote = SplitPair("dusk:usd")
+	assert.Equal(t, "DUSK", base)
+	assert.Equal(t, "USD", quote)
+}
+
+func TestConvertSymbols(t *testing.T) {
+	f := BitfinexFetcher{}
+	symbols, err := f.ConvertSymbols([]bitfinex.Pair{
+		{Pair: "btcusd", PricePrecision: 5, Margin: true},
+		{Pair: "ethbtc", PricePrecision: 5}})
+	assert.NoError(t, err)
+	assert.Len(t, symbols, 4)
+	assert.Equal(t, "tBTCUSD", symbols[0].Symbol)
+	assert.Equal(t, "BTC", symbols[0].BaseAsset)
+	assert.Equal(t, "USD", symbols[0].QuoteAsset)
+	assert.Equal(t, types.InstrumentTypeSpot, symbols[0].Type)
+
+	funding := map[string]string{}
+	for _, s := range symbols[2:] {
+		assert.Equal(t, types.InstrumentTypeFunding, s.Type)
+		funding[s.Symbol] = s.BaseAsset
+	}
+	assert.Equal(t, map[string]string{"fBTC": "BTC", "fUSD": "USD"}, funding)
+}
+
+func TestBitfinexParseSymbols(t *testing.T) {
+	f := BitfinexFetcher{}
+	fetchedAt := time.Date(2019, 1, 1, 10, 0, 0, 0, time.UTC)
+	raw := []byte(`[{"pair":"btcusd","price_precision":5,"initial_margin":"30.0","minimum_margin":"15.0",
+		"maximum_order_size":"2000.0","minimum_order_size":"0.0006","expiration":"NA","margin":true}]`)
+	snapshot, err := f.ParseSymbols(raw, fetchedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1546336800000), snapshot.SnapshotTime)
+	assert.Equal(t, 2019, snapshot.Year)
+	assert.Len(t, snapshot.Symbols, 3)
+	assert.Equal(t, "0.0006", snapshot.Symbols[0].Filters[0].Params["minQty"])
+
+	_, err = f.ParseSymbols([]byte(`{"error":"ERR_RATE_LIMIT"}`), fetchedAt)
+	assert.Error(t, err)
+}
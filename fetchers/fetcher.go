@@ -2,10 +2,15 @@ package fetchers
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/etrubenok/make-trades-types/registry"
 	"github.com/golang/glog"
 
+	"github.com/etrubenok/make-trades-registry/metrics"
 	"github.com/etrubenok/make-trades-registry/types"
 )
 
@@ -18,6 +23,57 @@ func GetYearMonthDay(timestamp int64) (int, int, int) {
 // Fetcher is the interface for all the fetchers
 type Fetcher interface {
 	FetchSymbols() (*types.ExchangeSymbols, error)
+	// ParseSymbols converts the raw response of the exchange fetched at fetchedAt into the snapshot,
+	// it is used for both the new and the archived responses
+	ParseSymbols(raw []byte, fetchedAt time.Time) (*types.ExchangeSymbols, error)
+}
+
+// fetchTimeout is the maximum time of a request to an exchange including the reading of the response
+const fetchTimeout = 30 * time.Second
+
+// httpClient is the client of the requests to the exchanges, a hanging exchange must not stop the fetches
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+// FetchRaw requests the url and returns the body of a successful response
+func FetchRaw(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		glog.Errorf("FetchRaw: cannot request '%s' due to error %s", url, err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		glog.Errorf("FetchRaw: cannot read the response from '%s' due to error %s", url, err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("FetchRaw: '%s' responded with status %d and body %s", url, resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// NewExchangeSymbols creates the snapshot of the symbols of the exchange taken at snapshotTime
+func NewExchangeSymbols(exchange string, snapshotTime time.Time, symbols []types.SymbolInfo) (*types.ExchangeSymbols, error) {
+	exchangeID, err := registry.GetExchangeID(exchange)
+	if err != nil {
+		glog.Errorf("NewExchangeSymbols: cannot get exchangeID for '%s' due to error %s", exchange, err)
+		return nil, err
+	}
+	r := types.ExchangeSymbols{
+		ExchangeID:   exchangeID,
+		SnapshotTime: snapshotTime.UnixNano() / int64(time.Millisecond),
+		Symbols:      symbols,
+	}
+
+	year, month, day := GetYearMonthDay(r.SnapshotTime)
+	glog.V(1).Infof("NewExchangeSymbols: exchange: %s, year: %d, month: %d, day: %d", exchange, year, month, day)
+
+	r.Year = year
+	r.Month = month
+	r.Day = day
+
+	return &r, nil
 }
 
 // FetcherFactory creates a required fetcher based on the exchange name
@@ -32,15 +88,22 @@ func FetcherFactory(exchange string) (Fetcher, error) {
 	}
 }
 
+// FetchPeriod is the period of the fetches of the symbols of the exchanges
+const FetchPeriod = 1 * time.Minute
+
 // FetchJob is the interface for fetch job
 type FetchJob interface {
 	Init(exchanges []string, results chan<- types.ExchangesSymbols)
+	// Stop stops the fetching, no results are sent afterwards
+	Stop()
 }
 
 // FetchJobImpl is an implementation of FetchJob
 type FetchJobImpl struct {
 	exchanges []string
 	ticker    *time.Ticker
+	stop      chan struct{}
+	stopOnce  sync.Once
 }
 
 // NewFetchJob instantiates a fetch job
@@ -52,14 +115,25 @@ func NewFetchJob() FetchJob {
 // Init initialises the fetch job with the list of exchanges
 func (j *FetchJobImpl) Init(exchanges []string, results chan<- types.ExchangesSymbols) {
 	j.exchanges = exchanges
-	j.ticker = time.NewTicker(1 * time.Minute)
+	j.ticker = time.NewTicker(FetchPeriod)
+	j.stop = make(chan struct{})
 	go j.FetchExchangesSymbols(results)
 }
 
-// FetchExchangesSymbols executes fetching across all the exchanges
+// Stop stops the ticker and the fetching goroutine
+func (j *FetchJobImpl) Stop() {
+	j.stopOnce.Do(func() {
+		j.ticker.Stop()
+		close(j.stop)
+	})
+}
+
+// FetchExchangesSymbols executes fetching across all the exchanges until the job is stopped
 func (j *FetchJobImpl) FetchExchangesSymbols(results chan<- types.ExchangesSymbols) {
 	for {
 		select {
+		case <-j.stop:
+			return
 		case <-j.ticker.C:
 			exchangeChan := make(chan types.ExchangeSymbols)
 			errorChan := make(chan error)
@@ -82,7 +156,11 @@ func (j *FetchJobImpl) FetchExchangesSymbols(results chan<- types.ExchangesSymbo
 					i++
 				}
 			}
-			results <- exchangesSymbols
+			select {
+			case results <- exchangesSymbols:
+			case <-j.stop:
+				return
+			}
 		}
 	}
 }
@@ -95,7 +173,9 @@ func (j *FetchJobImpl) FetchExchange(exchange string, exchangeChan chan<- types.
 		errorChan <- err
 		return
 	}
+	start := time.Now()
 	exSymbols, err := fetcher.FetchSymbols()
+	metrics.ObserveFetch(exchange, start, err)
 	if err != nil {
 		glog.Errorf("FetchExchange: cannot fetch from exchange '%s' due to error '%s'", exchange, err)
 		errorChan <- err
@@ -0,0 +1,316 @@
+package filter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Separator separates the values of the multi-value query parameters, e.g. 'exchanges=binance@bitfinex'
+const Separator = "@"
+
+// MaxLimit is the maximum number of symbols returned in one page
+const MaxLimit = 10000
+
+// ParameterError type is the error of a malformed filter query parameter
+type ParameterError struct {
+	Parameter string
+	Message   string
+}
+
+// Error returns the message of the error
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("invalid parameter '%s': %s", e.Parameter, e.Message)
+}
+
+// Filter type selects the symbols by their attributes, projects them onto a set of fields and pages them.
+// Empty sets match everything
+type Filter struct {
+	Exchanges   map[string]bool
+	Symbols     map[string]bool
+	Statuses    map[string]bool
+	BaseAssets  map[string]bool
+	QuoteAssets map[string]bool
+	Types       map[string]bool
+	Prefix      string
+	Regex       *regexp.Regexp
+	Fields      []string
+	Limit       int
+	Cursor      string
+}
+
+// NewFilter creates a filter which matches everything
+func NewFilter() *Filter {
+	f := Filter{
+		Exchanges:   make(map[string]bool),
+		Symbols:     make(map[string]bool),
+		Statuses:    make(map[string]bool),
+		BaseAssets:  make(map[string]bool),
+		QuoteAssets: make(map[string]bool),
+		Types:       make(map[string]bool)}
+	return &f
+}
+
+// ParseQuery creates a filter from the query parameters 'exchanges', 'symbols', 'status', 'base', 'quote', 'type',
+// 'prefix', 'regex', 'fields', 'limit' and 'cursor'
+func ParseQuery(query url.Values) (*Filter, error) {
+	f := NewFilter()
+	f.Exchanges = parseSet(query.Get("exchanges"), false)
+	f.Symbols = parseSet(query.Get("symbols"), false)
+	f.Statuses = parseSet(query.Get("status"), true)
+	f.BaseAssets = parseSet(query.Get("base"), true)
+	f.QuoteAssets = parseSet(query.Get("quote"), true)
+	f.Types = parseSet(query.Get("type"), false)
+	f.Prefix = query.Get("prefix")
+	f.Cursor = query.Get("cursor")
+
+	if r := query.Get("regex"); r != "" {
+		regex, err := regexp.Compile(r)
+		if err != nil {
+			glog.Errorf("ParseQuery: cannot compile regex '%s' due to error %s", r, err)
+			return nil, &ParameterError{Parameter: "regex", Message: err.Error()}
+		}
+		f.Regex = regex
+	}
+	if fields := query.Get("fields"); fields != "" {
+		f.Fields = strings.Split(fields, Separator)
+		for _, field := range f.Fields {
+			if !isSymbolField(field) {
+				return nil, &ParameterError{Parameter: "fields", Message: fmt.Sprintf("unknown field '%s'", field)}
+			}
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 {
+			return nil, &ParameterError{Parameter: "limit", Message: fmt.Sprintf("limit '%s' is not a positive integer", limit)}
+		}
+		if l > MaxLimit {
+			l = MaxLimit
+		}
+		f.Limit = l
+	}
+	if f.Cursor != "" {
+		if _, _, err := decodeCursor(f.Cursor); err != nil {
+			return nil, &ParameterError{Parameter: "cursor", Message: err.Error()}
+		}
+	}
+	return f, nil
+}
+
+func parseSet(value string, upper bool) map[string]bool {
+	r := make(map[string]bool)
+	if value == "" {
+		return r
+	}
+	for _, v := range strings.Split(value, Separator) {
+		if upper {
+			v = strings.ToUpper(v)
+		}
+		r[v] = true
+	}
+	return r
+}
+
+func isSymbolField(field string) bool {
+	for _, f := range SymbolFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSet(set map[string]bool, value string) bool {
+	return len(set) == 0 || set[value]
+}
+
+// MatchExchange returns true if the exchange passes the filter
+func (f *Filter) MatchExchange(exchange string) bool {
+	return matchSet(f.Exchanges, exchange)
+}
+
+// MatchSymbolInfo returns true if the symbol of the exchange in DB format passes the filter
+func (f *Filter) MatchSymbolInfo(exchange string, s *types.SymbolInfo) bool {
+	return f.match(exchange, s.Symbol, types.NewInstrumentID(exchange, s).String(), s.Status, s.BaseAsset, s.QuoteAsset, s.Type)
+}
+
+// Match returns true if the symbol of the exchange in API format passes the filter
+func (f *Filter) Match(exchange string, s *types.APISymbolInfo) bool {
+	return f.match(exchange, strings.TrimPrefix(s.Symbol, exchange+"-"), s.CanonicalID, s.Status, s.Asset, s.Quote, s.Type)
+}
+
+func (f *Filter) match(exchange, symbol, canonicalID, status, base, quote, instrumentType string) bool {
+	if !f.MatchExchange(exchange) {
+		return false
+	}
+	if len(f.Symbols) > 0 && !f.Symbols[symbol] && !f.Symbols[exchange+"-"+symbol] && !f.Symbols[canonicalID] {
+		return false
+	}
+	if !matchSet(f.Statuses, strings.ToUpper(status)) ||
+		!matchSet(f.BaseAssets, strings.ToUpper(base)) ||
+		!matchSet(f.QuoteAssets, strings.ToUpper(quote)) ||
+		!matchSet(f.Types, instrumentType) {
+		return false
+	}
+	if f.Prefix != "" && !strings.HasPrefix(symbol, f.Prefix) {
+		return false
+	}
+	if f.Regex != nil && !f.Regex.MatchString(symbol) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the symbols passing the filter. If the limit is set, the symbols of every exchange are sorted
+// and only one page of them starting after the cursor is returned together with the cursor of the next page
+func (f *Filter) Apply(r *types.APIExchangesSymbols) *types.APIExchangesSymbols {
+	filtered := types.APIExchangesSymbols{
+		Exchanges: make([]types.APIExchangeSymbols, 0, len(r.Exchanges))}
+
+	cursorExchange, cursorSymbol := "", ""
+	if f.Cursor != "" {
+		// The cursor is validated by ParseQuery
+		cursorExchange, cursorSymbol, _ = decodeCursor(f.Cursor)
+	}
+	passedCursor := f.Cursor == ""
+	count := 0
+	lastExchange, lastSymbol := "", ""
+	for _, e := range r.Exchanges {
+		if !f.MatchExchange(e.Exchange) {
+			continue
+		}
+		startAfter := ""
+		if !passedCursor {
+			if e.Exchange != cursorExchange {
+				continue
+			}
+			startAfter = cursorSymbol
+			passedCursor = true
+		}
+
+		symbols := e.Symbols
+		if f.Limit > 0 {
+			symbols = make([]types.APISymbolInfo, len(e.Symbols))
+			copy(symbols, e.Symbols)
+			sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+		}
+		exchange := e
+		exchange.Symbols = make([]types.APISymbolInfo, 0)
+		for i := range symbols {
+			s := &symbols[i]
+			if startAfter != "" && s.Symbol <= startAfter {
+				continue
+			}
+			if !f.Match(e.Exchange, s) {
+				continue
+			}
+			if f.Limit > 0 && count == f.Limit {
+				filtered.NextCursor = encodeCursor(lastExchange, lastSymbol)
+				break
+			}
+			exchange.Symbols = append(exchange.Symbols, *s)
+			count++
+			lastExchange, lastSymbol = e.Exchange, s.Symbol
+		}
+		if len(exchange.Symbols) > 0 || f.Limit == 0 {
+			filtered.Exchanges = append(filtered.Exchanges, exchange)
+		}
+		if filtered.NextCursor != "" {
+			break
+		}
+	}
+	return &filtered
+}
+
+// SymbolFields are the fields of a symbol in API format in the order of the CSV columns
+var SymbolFields = []string{"symbol", "native_symbol", "canonical_id", "status", "type", "asset", "quote"}
+
+// SymbolField returns the value of the field of the symbol by its name in the JSON response
+func SymbolField(s *types.APISymbolInfo, field string) string {
+	switch field {
+	case "symbol":
+		return s.Symbol
+	case "native_symbol":
+		return s.NativeSymbol
+	case "canonical_id":
+		return s.CanonicalID
+	case "status":
+		return s.Status
+	case "type":
+		return s.Type
+	case "asset":
+		return s.Asset
+	case "quote":
+		return s.Quote
+	}
+	return ""
+}
+
+// ProjectExchanges returns the response with the symbols restricted to the fields in the shape of the JSON response
+func ProjectExchanges(r *types.APIExchangesSymbols, fields []string) map[string]interface{} {
+	exchanges := make([]map[string]interface{}, len(r.Exchanges))
+	for i := range r.Exchanges {
+		exchanges[i] = ProjectExchange(&r.Exchanges[i], fields)
+	}
+	projected := map[string]interface{}{"exchanges": exchanges}
+	if r.NextCursor != "" {
+		projected["next_cursor"] = r.NextCursor
+	}
+	return projected
+}
+
+// ProjectExchange returns the snapshot of the exchange with the symbols restricted to the fields
+// in the shape of the JSON response
+func ProjectExchange(e *types.APIExchangeSymbols, fields []string) map[string]interface{} {
+	symbols := make([]map[string]string, len(e.Symbols))
+	for j := range e.Symbols {
+		symbols[j] = make(map[string]string, len(fields))
+		for _, field := range fields {
+			symbols[j][field] = SymbolField(&e.Symbols[j], field)
+		}
+	}
+	projected := map[string]interface{}{
+		"exchange":      e.Exchange,
+		"snapshot_date": e.SnapshotDate,
+		"snapshot_time": e.SnapshotTime,
+		"content_hash":  e.ContentHash,
+		"symbols":       symbols}
+	if e.Freshness != nil {
+		projected["freshness"] = e.Freshness
+	}
+	return projected
+}
+
+// Project returns the response restricted to the fields of the symbols listed in the filter.
+// If no fields are listed, the response is returned as is
+func (f *Filter) Project(r *types.APIExchangesSymbols) interface{} {
+	if len(f.Fields) == 0 {
+		return r
+	}
+	return ProjectExchanges(r, f.Fields)
+}
+
+func encodeCursor(exchange string, symbol string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(exchange + "|" + symbol))
+}
+
+func decodeCursor(cursor string) (string, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+	parts := strings.SplitN(string(data), "|", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSymbols() *types.APIExchangesSymbols {
+	return &types.APIExchangesSymbols{
+		Exchanges: []types.APIExchangeSymbols{
+			{Exchange: "binance", Symbols: []types.APISymbolInfo{
+				{Symbol: "binance-ETHBTC", Status: "TRADING", Type: "spot", Asset: "ETH", Quote: "BTC"},
+				{Symbol: "binance-BTCUSDT", Status: "TRADING", Type: "spot", Asset: "BTC", Quote: "USDT"},
+				{Symbol: "binance-XRPBTC", Status: "BREAK", Type: "spot", Asset: "XRP", Quote: "BTC"}}},
+			{Exchange: "bitfinex", Symbols: []types.APISymbolInfo{
+				{Symbol: "bitfinex-tBTCUSD", Status: "TRADING", Type: "spot", Asset: "BTC", Quote: "USD"},
+				{Symbol: "bitfinex-fUSD", Status: "TRADING", Type: "funding", Asset: "USD"}}}}}
+}
+
+func parse(t *testing.T, query string) *Filter {
+	values, err := url.ParseQuery(query)
+	assert.NoError(t, err)
+	f, err := ParseQuery(values)
+	assert.NoError(t, err)
+	return f
+}
+
+func symbolNames(r *types.APIExchangesSymbols) []string {
+	names := []string{}
+	for _, e := range r.Exchanges {
+		for _, s := range e.Symbols {
+			names = append(names, s.Symbol)
+		}
+	}
+	return names
+}
+
+func TestApplyAttributes(t *testing.T) {
+	r := parse(t, "status=trading&quote=BTC").Apply(testSymbols())
+	assert.Equal(t, []string{"binance-ETHBTC"}, symbolNames(r))
+
+	r = parse(t, "type=funding").Apply(testSymbols())
+	assert.Equal(t, []string{"bitfinex-fUSD"}, symbolNames(r))
+
+	r = parse(t, "base=BTC@ETH&exchanges=binance").Apply(testSymbols())
+	assert.Equal(t, []string{"binance-ETHBTC", "binance-BTCUSDT"}, symbolNames(r))
+}
+
+func TestApplyPrefixAndRegex(t *testing.T) {
+	r := parse(t, "prefix=t").Apply(testSymbols())
+	assert.Equal(t, []string{"bitfinex-tBTCUSD"}, symbolNames(r))
+
+	r = parse(t, "regex=BTC$").Apply(testSymbols())
+	assert.Equal(t, []string{"binance-ETHBTC", "binance-XRPBTC"}, symbolNames(r))
+}
+
+func TestApplyPagination(t *testing.T) {
+	r := parse(t, "limit=2").Apply(testSymbols())
+	assert.Equal(t, []string{"binance-BTCUSDT", "binance-ETHBTC"}, symbolNames(r))
+	assert.NotEmpty(t, r.NextCursor)
+
+	r = parse(t, "limit=2&cursor="+r.NextCursor).Apply(testSymbols())
+	assert.Equal(t, []string{"binance-XRPBTC", "bitfinex-fUSD"}, symbolNames(r))
+	assert.NotEmpty(t, r.NextCursor)
+
+	r = parse(t, "limit=2&cursor="+r.NextCursor).Apply(testSymbols())
+	assert.Equal(t, []string{"bitfinex-tBTCUSD"}, symbolNames(r))
+	assert.Empty(t, r.NextCursor)
+}
+
+func TestProject(t *testing.T) {
+	f := parse(t, "fields=symbol@quote&exchanges=bitfinex&type=spot")
+	projected := f.Project(f.Apply(testSymbols()))
+
+	exchanges := projected.(map[string]interface{})["exchanges"].([]map[string]interface{})
+	symbols := exchanges[0]["symbols"].([]map[string]string)
+	assert.Equal(t, map[string]string{"symbol": "bitfinex-tBTCUSD", "quote": "USD"}, symbols[0])
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{"regex=(", "fields=unknown", "limit=0", "limit=x", "cursor=%25%25"} {
+		values, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+		_, err = ParseQuery(values)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestMatchSymbolInfo(t *testing.T) {
+	f := parse(t, "symbols=binance-ETHBTC")
+	assert.True(t, f.MatchSymbolInfo("binance", &types.SymbolInfo{Symbol: "ETHBTC"}))
+	assert.False(t, f.MatchSymbolInfo("binance", &types.SymbolInfo{Symbol: "XRPBTC"}))
+}
+
+func TestMatchCanonicalID(t *testing.T) {
+	f := parse(t, "symbols=binance:spot:ETH/BTC@bitfinex:funding:USD")
+	assert.True(t, f.MatchSymbolInfo("binance", &types.SymbolInfo{Symbol: "ETHBTC", Type: "spot", BaseAsset: "ETH", QuoteAsset: "BTC"}))
+	assert.True(t, f.MatchSymbolInfo("bitfinex", &types.SymbolInfo{Symbol: "fUSD", Type: "funding", BaseAsset: "USD"}))
+	assert.False(t, f.MatchSymbolInfo("binance", &types.SymbolInfo{Symbol: "XRPBTC", Type: "spot", BaseAsset: "XRP", QuoteAsset: "BTC"}))
+}
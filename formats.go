@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/formats"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// NegotiateFormat returns the response format given by the 'format' query parameter or else by the Accept header.
+// Only an unsupported 'format' is not acceptable, the unsupported Accept headers get JSON
+func NegotiateFormat(c *gin.Context) (formats.Format, error) {
+	if name := c.Query("format"); name != "" {
+		f, err := formats.ParseFormat(name)
+		if err != nil {
+			glog.Errorf("NegotiateFormat: %s", err)
+			return "", NewNotAcceptableError(name)
+		}
+		return f, nil
+	}
+	return formats.Negotiate(c.GetHeader("Accept")), nil
+}
+
+// respondSymbols writes the symbols in the format other than JSON. The cursor of the next page is passed in
+// the X-Next-Cursor header as the row formats have no place for it
+func respondSymbols(c *gin.Context, f formats.Format, r *types.APIExchangesSymbols, fields []string) {
+	if r.NextCursor != "" {
+		c.Header("X-Next-Cursor", r.NextCursor)
+	}
+	c.Header("Content-Type", f.ContentType())
+	c.Status(http.StatusOK)
+	if err := formats.Write(c.Writer, f, r, fields); err != nil {
+		glog.Errorf("respondSymbols: cannot write the response on %s due to error %s", c.Request.URL.Path, err)
+	}
+}
+
+// respondSnapshot writes the snapshot of one exchange in the format other than JSON
+func respondSnapshot(c *gin.Context, f formats.Format, e *types.APIExchangeSymbols) {
+	c.Header("Content-Type", f.ContentType())
+	c.Status(http.StatusOK)
+	if err := formats.WriteExchange(c.Writer, f, e, nil); err != nil {
+		glog.Errorf("respondSnapshot: cannot write the response on %s due to error %s", c.Request.URL.Path, err)
+	}
+}
@@ -0,0 +1,217 @@
+// Package formats encodes the symbols in API format into the response formats negotiated with the client
+package formats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/etrubenok/make-trades-registry/filter"
+	"github.com/etrubenok/make-trades-registry/registrypb"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Format is the encoding of the response
+type Format string
+
+// The supported response formats
+const (
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	CSV      Format = "csv"
+	MsgPack  Format = "msgpack"
+	Protobuf Format = "protobuf"
+)
+
+// contentTypes maps the media types onto the formats, the first media type of a format is the one it is served with
+var contentTypes = []struct {
+	mediaType string
+	format    Format
+}{
+	{"application/json", JSON},
+	{"application/x-ndjson", NDJSON},
+	{"application/ndjson", NDJSON},
+	{"text/csv", CSV},
+	{"application/msgpack", MsgPack},
+	{"application/x-msgpack", MsgPack},
+	{"application/x-protobuf", Protobuf},
+	{"application/protobuf", Protobuf},
+}
+
+// exchangeFields are the fields of the snapshot repeated in every row of the row formats
+var exchangeFields = []string{"exchange", "snapshot_date", "snapshot_time"}
+
+// ParseFormat returns the format by its name as given in the 'format' query parameter
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case JSON, NDJSON, CSV, MsgPack, Protobuf:
+		return f, nil
+	}
+	return "", fmt.Errorf("ParseFormat: unknown format '%s'", name)
+}
+
+// Negotiate returns the format matching the Accept header the best. The media types are tried in the order
+// of their quality. JSON is returned for an empty header and if none of the accepted media types is supported,
+// so the clients not asking for a supported format get the JSON they always got
+func Negotiate(accept string) Format {
+	if strings.TrimSpace(accept) == "" {
+		return JSON
+	}
+	type accepted struct {
+		mediaType string
+		quality   float64
+	}
+	ranges := make([]accepted, 0)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		a := accepted{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					a.quality = q
+				}
+			}
+		}
+		if a.quality > 0 {
+			ranges = append(ranges, a)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	for _, a := range ranges {
+		for _, t := range contentTypes {
+			if a.mediaType == t.mediaType || a.mediaType == "*/*" ||
+				(strings.HasSuffix(a.mediaType, "/*") && strings.HasPrefix(t.mediaType, strings.TrimSuffix(a.mediaType, "*"))) {
+				return t.format
+			}
+		}
+	}
+	return JSON
+}
+
+// ContentType returns the media type the format is served with
+func (f Format) ContentType() string {
+	for _, t := range contentTypes {
+		if t.format == f {
+			if f == CSV {
+				return t.mediaType + "; charset=utf-8"
+			}
+			return t.mediaType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// Write encodes the symbols in the format. If fields are given, the symbols are restricted to them
+// except in protobuf which has a fixed schema. The row formats have one row per symbol prefixed by its exchange
+// and snapshot, the cursor of the next page is left to the caller
+func Write(w io.Writer, f Format, r *types.APIExchangesSymbols, fields []string) error {
+	if len(fields) == 0 {
+		fields = filter.SymbolFields
+	}
+	var err error
+	switch f {
+	case JSON:
+		err = json.NewEncoder(w).Encode(filter.ProjectExchanges(r, fields))
+	case NDJSON:
+		err = writeNDJSON(w, r, fields)
+	case CSV:
+		err = writeCSV(w, r, fields)
+	case MsgPack:
+		err = codec.NewEncoder(w, &codec.MsgpackHandle{}).Encode(filter.ProjectExchanges(r, fields))
+	case Protobuf:
+		var data []byte
+		data, err = proto.Marshal(registrypb.FromAPIExchangesSymbols(r))
+		if err == nil {
+			_, err = w.Write(data)
+		}
+	default:
+		err = fmt.Errorf("unknown format '%s'", f)
+	}
+	if err != nil {
+		glog.Errorf("Write: cannot write the symbols in format '%s' due to error %s", f, err)
+		return err
+	}
+	return nil
+}
+
+// WriteExchange encodes the snapshot of one exchange in the format. JSON, MessagePack and protobuf encode the snapshot
+// itself rather than a list of the snapshots, the row formats are the same as the ones of Write
+func WriteExchange(w io.Writer, f Format, e *types.APIExchangeSymbols, fields []string) error {
+	if len(fields) == 0 {
+		fields = filter.SymbolFields
+	}
+	var err error
+	switch f {
+	case JSON:
+		err = json.NewEncoder(w).Encode(filter.ProjectExchange(e, fields))
+	case MsgPack:
+		err = codec.NewEncoder(w, &codec.MsgpackHandle{}).Encode(filter.ProjectExchange(e, fields))
+	case Protobuf:
+		var data []byte
+		data, err = proto.Marshal(registrypb.FromAPIExchangeSymbols(e))
+		if err == nil {
+			_, err = w.Write(data)
+		}
+	default:
+		return Write(w, f, &types.APIExchangesSymbols{Exchanges: []types.APIExchangeSymbols{*e}}, fields)
+	}
+	if err != nil {
+		glog.Errorf("WriteExchange: cannot write the snapshot in format '%s' due to error %s", f, err)
+		return err
+	}
+	return nil
+}
+
+func writeNDJSON(w io.Writer, r *types.APIExchangesSymbols, fields []string) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for i := range r.Exchanges {
+		e := &r.Exchanges[i]
+		for j := range e.Symbols {
+			row := map[string]interface{}{
+				"exchange":      e.Exchange,
+				"snapshot_date": e.SnapshotDate,
+				"snapshot_time": e.SnapshotTime}
+			for _, field := range fields {
+				row[field] = filter.SymbolField(&e.Symbols[j], field)
+			}
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+	}
+	return buffered.Flush()
+}
+
+func writeCSV(w io.Writer, r *types.APIExchangesSymbols, fields []string) error {
+	writer := csv.NewWriter(w)
+	header := append(append(make([]string, 0, len(exchangeFields)+len(fields)), exchangeFields...), fields...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := range r.Exchanges {
+		e := &r.Exchanges[i]
+		for j := range e.Symbols {
+			row := []string{e.Exchange, e.SnapshotDate, strconv.FormatInt(e.SnapshotTime, 10)}
+			for _, field := range fields {
+				row = append(row, filter.SymbolField(&e.Symbols[j], field))
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
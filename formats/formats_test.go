@@ -0,0 +1,118 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/etrubenok/make-trades-registry/registrypb"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testSymbols() *types.APIExchangesSymbols {
+	return &types.APIExchangesSymbols{
+		Exchanges: []types.APIExchangeSymbols{
+			{Exchange: "binance", SnapshotDate: "2019-01-01", SnapshotTime: 1546300800000, Symbols: []types.APISymbolInfo{
+				{Symbol: "binance-ETHBTC", NativeSymbol: "ETHBTC", CanonicalID: "binance:spot:ETH/BTC", Status: "TRADING", Type: "spot", Asset: "ETH", Quote: "BTC"},
+				{Symbol: "binance-XRPBTC", NativeSymbol: "XRPBTC", CanonicalID: "binance:spot:XRP/BTC", Status: "BREAK", Type: "spot", Asset: "XRP", Quote: "BTC"}}}},
+		NextCursor: "cursor"}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, CSV, f)
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]Format{
+		"":                                      JSON,
+		"text/csv":                              CSV,
+		"application/x-ndjson":                  NDJSON,
+		"application/x-msgpack":                 MsgPack,
+		"application/x-protobuf, */*;q=0.1":     Protobuf,
+		"text/html, application/xml;q=0.9, */*": JSON,
+		"application/json;q=0.5, text/csv":      CSV,
+		"text/*":                                CSV,
+		"text/html, application/xml":            JSON,
+		"text/plain":                            JSON,
+		"text/csv;q=0":                          JSON,
+	}
+	for accept, expected := range cases {
+		assert.Equal(t, expected, Negotiate(accept), accept)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, CSV, testSymbols(), nil))
+	rows, err := csv.NewReader(&b).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, []string{"exchange", "snapshot_date", "snapshot_time", "symbol", "native_symbol", "canonical_id", "status", "type", "asset", "quote"}, rows[0])
+	assert.Equal(t, []string{"binance", "2019-01-01", "1546300800000", "binance-ETHBTC", "ETHBTC", "binance:spot:ETH/BTC", "TRADING", "spot", "ETH", "BTC"}, rows[1])
+
+	b.Reset()
+	assert.NoError(t, Write(&b, CSV, testSymbols(), []string{"symbol", "status"}))
+	assert.Equal(t, "exchange,snapshot_date,snapshot_time,symbol,status\n"+
+		"binance,2019-01-01,1546300800000,binance-ETHBTC,TRADING\n"+
+		"binance,2019-01-01,1546300800000,binance-XRPBTC,BREAK\n", b.String())
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, NDJSON, testSymbols(), []string{"symbol"}))
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	var row map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+	assert.Equal(t, map[string]interface{}{
+		"exchange": "binance", "snapshot_date": "2019-01-01", "snapshot_time": float64(1546300800000), "symbol": "binance-XRPBTC"}, row)
+}
+
+func TestWriteMsgPack(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, MsgPack, testSymbols(), nil))
+	var r types.APIExchangesSymbols
+	assert.NoError(t, codec.NewDecoder(&b, &codec.MsgpackHandle{}).Decode(&r))
+	assert.Equal(t, *testSymbols(), r)
+}
+
+func TestWriteProtobuf(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, Write(&b, Protobuf, testSymbols(), []string{"symbol"}))
+	var r registrypb.ApiExchangesSymbols
+	assert.NoError(t, proto.Unmarshal(b.Bytes(), &r))
+	assert.Equal(t, "cursor", r.NextCursor)
+	assert.Equal(t, 2, len(r.Exchanges[0].Symbols))
+	assert.Equal(t, "binance:spot:XRP/BTC", r.Exchanges[0].Symbols[1].CanonicalId)
+}
+
+func TestWriteExchange(t *testing.T) {
+	e := &testSymbols().Exchanges[0]
+	var b bytes.Buffer
+	assert.NoError(t, WriteExchange(&b, MsgPack, e, []string{"symbol"}))
+	var r types.APIExchangeSymbols
+	assert.NoError(t, codec.NewDecoder(&b, &codec.MsgpackHandle{}).Decode(&r))
+	assert.Equal(t, "binance", r.Exchange)
+	assert.Equal(t, "binance-XRPBTC", r.Symbols[1].Symbol)
+
+	b.Reset()
+	assert.NoError(t, WriteExchange(&b, Protobuf, e, nil))
+	var pb registrypb.ApiExchangeSymbols
+	assert.NoError(t, proto.Unmarshal(b.Bytes(), &pb))
+	assert.Equal(t, "binance", pb.Exchange)
+	assert.Equal(t, 2, len(pb.Symbols))
+
+	b.Reset()
+	assert.NoError(t, WriteExchange(&b, CSV, e, []string{"symbol"}))
+	assert.Equal(t, 3, len(strings.Split(strings.TrimSpace(b.String()), "\n")))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/formats"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	c, _ := testContext("/symbols?format=csv")
+	c.Request.Header.Set("Accept", "application/json")
+	f, err := NegotiateFormat(c)
+	assert.NoError(t, err)
+	assert.Equal(t, formats.CSV, f)
+
+	c, _ = testContext("/symbols")
+	c.Request.Header.Set("Accept", "application/x-msgpack")
+	f, err = NegotiateFormat(c)
+	assert.NoError(t, err)
+	assert.Equal(t, formats.MsgPack, f)
+
+	c, _ = testContext("/symbols?format=xml")
+	_, err = NegotiateFormat(c)
+	assert.Equal(t, http.StatusNotAcceptable, err.(*HTTPError).Status)
+
+	c, _ = testContext("/symbols")
+	c.Request.Header.Set("Accept", "text/plain")
+	f, err = NegotiateFormat(c)
+	assert.NoError(t, err)
+	assert.Equal(t, formats.JSON, f)
+}
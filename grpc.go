@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/etrubenok/make-trades-registry/filter"
+	"github.com/etrubenok/make-trades-registry/registrypb"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// RegistryServer implements the gRPC API of the registry on top of the same storage and stream as the HTTP API
+type RegistryServer struct {
+	registrypb.UnimplementedSymbolsRegistryServer
+}
+
+// NewRegistryServer instantiates RegistryServer object
+func NewRegistryServer() *RegistryServer {
+	s := RegistryServer{}
+	return &s
+}
+
+// GRPCError converts the error into the gRPC status error with the code matching the HTTP status
+func GRPCError(err error) error {
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		return status.Error(codes.Internal, "internal server error")
+	}
+	code := codes.Internal
+	switch httpErr.Status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+	return status.Error(code, httpErr.APIError.Message)
+}
+
+// filterQuery converts the gRPC filter into the query parameters of the HTTP API
+func filterQuery(f *registrypb.SymbolsFilter) url.Values {
+	query := make(url.Values)
+	if f == nil {
+		return query
+	}
+	set := func(name string, values []string) {
+		if len(values) > 0 {
+			query.Set(name, strings.Join(values, filter.Separator))
+		}
+	}
+	set("exchanges", f.Exchanges)
+	set("symbols", f.Symbols)
+	set("status", f.Statuses)
+	set("base", f.BaseAssets)
+	set("quote", f.QuoteAssets)
+	set("type", f.Types)
+	if f.Prefix != "" {
+		query.Set("prefix", f.Prefix)
+	}
+	if f.Regex != "" {
+		query.Set("regex", f.Regex)
+	}
+	return query
+}
+
+// snapshotQuery creates the snapshot query from the time in milliseconds since epoch or the date
+func snapshotQuery(at int64, date string) (*SnapshotQuery, error) {
+	if at != 0 {
+		atTime := time.Unix(0, at*int64(time.Millisecond)).UTC()
+		return NewSnapshotQuery(&atTime, "")
+	}
+	return NewSnapshotQuery(nil, date)
+}
+
+// GetSymbols returns the symbols of the exchanges passing the filter
+func (s *RegistryServer) GetSymbols(ctx context.Context, req *registrypb.GetSymbolsRequest) (*registrypb.GetSymbolsResponse, error) {
+	query := filterQuery(req.Filter)
+	if req.Limit != 0 {
+		query.Set("limit", strconv.Itoa(int(req.Limit)))
+	}
+	if req.Cursor != "" {
+		query.Set("cursor", req.Cursor)
+	}
+	f, err := ParseFilterQuery(query)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	exchanges := GetAllExchanges()
+	if req.Filter != nil && len(req.Filter.Exchanges) > 0 {
+		exchanges = req.Filter.Exchanges
+	}
+	q, err := snapshotQuery(req.At, req.Date)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	snapshots, err := LoadExchangesSnapshots(exchanges, q)
+	if err != nil {
+		glog.Errorf("RegistryServer.GetSymbols: cannot load the snapshots of exchanges %v due to error %s", exchanges, err)
+		return nil, GRPCError(err)
+	}
+
+	// The filter works on the symbols in API format, the passed ones are taken back from the snapshots in DB format
+	apiSymbols, err := types.ConvertExchangeSymbolsToAPIResponse(snapshotsOf(exchanges, snapshots))
+	if err != nil {
+		glog.Errorf("RegistryServer.GetSymbols: cannot convert to API response due to error %s", err)
+		return nil, GRPCError(err)
+	}
+	filtered := f.Apply(apiSymbols)
+	r := registrypb.GetSymbolsResponse{
+		Exchanges:  make([]*registrypb.ExchangeSymbols, 0, len(filtered.Exchanges)),
+		NextCursor: filtered.NextCursor}
+	for _, e := range filtered.Exchanges {
+		snapshot := snapshots[e.Exchange]
+		bySymbol := make(map[string]*types.SymbolInfo, len(snapshot.Symbols))
+		for i := range snapshot.Symbols {
+			bySymbol[snapshot.Symbols[i].Symbol] = &snapshot.Symbols[i]
+		}
+		passed := *snapshot
+		passed.Symbols = make([]types.SymbolInfo, 0, len(e.Symbols))
+		for _, apiSymbol := range e.Symbols {
+			passed.Symbols = append(passed.Symbols, *bySymbol[apiSymbol.NativeSymbol])
+		}
+		pb := registrypb.FromExchangeSymbols(e.Exchange, &passed)
+		// The content hash identifies the whole snapshot rather than its filtered part
+		pb.ContentHash = e.ContentHash
+		r.Exchanges = append(r.Exchanges, pb)
+	}
+	return &r, nil
+}
+
+// snapshotsOf returns the snapshots of the exchanges in their order
+func snapshotsOf(exchanges []string, snapshots map[string]*types.ExchangeSymbols) *types.ExchangesSymbols {
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0, len(exchanges))}
+	for _, e := range exchanges {
+		r.Exchanges = append(r.Exchanges, *snapshots[e])
+	}
+	return &r
+}
+
+// GetSymbol returns the full information about one symbol
+func (s *RegistryServer) GetSymbol(ctx context.Context, req *registrypb.GetSymbolRequest) (*registrypb.GetSymbolResponse, error) {
+	q, err := snapshotQuery(req.At, req.Date)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	snapshots, err := LoadExchangesSnapshots([]string{req.Exchange}, q)
+	if err != nil {
+		glog.Errorf("RegistryServer.GetSymbol: cannot load the snapshot of exchange '%s' due to error %s", req.Exchange, err)
+		return nil, GRPCError(err)
+	}
+	snapshot := snapshots[req.Exchange]
+	for i := range snapshot.Symbols {
+		if snapshot.Symbols[i].Symbol == req.Symbol {
+			r := registrypb.GetSymbolResponse{
+				Exchange:     req.Exchange,
+				SnapshotTime: snapshot.SnapshotTime,
+				SnapshotDate: snapshot.SnapshotDate(),
+				Symbol:       registrypb.FromSymbolInfo(req.Exchange, &snapshot.Symbols[i])}
+			return &r, nil
+		}
+	}
+	suggestions := SuggestSymbols(req.Exchange, req.Symbol, snapshot.Symbols, maxSuggestions)
+	return nil, status.Error(codes.NotFound, fmt.Sprintf("symbol not found, similar symbols: %s", strings.Join(suggestions, ", ")))
+}
+
+// ListSnapshots returns one page of the stored snapshots of an exchange, the latest first
+func (s *RegistryServer) ListSnapshots(ctx context.Context, req *registrypb.ListSnapshotsRequest) (*registrypb.ListSnapshotsResponse, error) {
+	exchangeIDs, err := GetExchangeIDs([]string{req.Exchange})
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	to := time.Now().UTC()
+	if req.To != 0 {
+		to = time.Unix(0, req.To*int64(time.Millisecond)).UTC()
+	}
+	from := DefaultSnapshotsFrom(to)
+	if req.From != 0 {
+		from = time.Unix(0, req.From*int64(time.Millisecond)).UTC()
+	}
+	cursor := to.Add(time.Millisecond)
+	if req.Cursor != "" {
+		cursor, err = ParseTime(req.Cursor)
+		if err != nil {
+			return nil, GRPCError(NewInvalidParameterError("cursor", "invalid cursor"))
+		}
+	}
+	limit := defaultSnapshotsLimit
+	if req.Limit < 0 {
+		return nil, GRPCError(NewInvalidParameterError("limit", "'limit' must be a positive integer"))
+	}
+	if req.Limit > 0 {
+		limit = int(req.Limit)
+	}
+	if limit > maxSnapshotsLimit {
+		limit = maxSnapshotsLimit
+	}
+
+	list, err := ListExchangeSnapshots(req.Exchange, exchangeIDs[0], from, to, cursor, limit)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	r := registrypb.ListSnapshotsResponse{
+		Exchange:   list.Exchange,
+		Snapshots:  make([]*registrypb.SnapshotSummary, len(list.Snapshots)),
+		NextCursor: list.NextCursor}
+	for i, summary := range list.Snapshots {
+		r.Snapshots[i] = &registrypb.SnapshotSummary{
+			SnapshotTime: summary.SnapshotTime,
+			SnapshotDate: summary.SnapshotDate,
+			SymbolsCount: int32(summary.SymbolsCount),
+			ContentHash:  summary.ContentHash}
+	}
+	return &r, nil
+}
+
+// WatchChanges streams the current snapshots followed by the change events of the symbols passing the filter
+func (s *RegistryServer) WatchChanges(req *registrypb.WatchChangesRequest, srv registrypb.SymbolsRegistry_WatchChangesServer) error {
+	f, err := ParseFilterQuery(filterQuery(req.Filter))
+	if err != nil {
+		return GRPCError(err)
+	}
+	subscription, initial := hub.Subscribe(f, req.LastEventId)
+	defer hub.Unsubscribe(subscription)
+
+	for i := range initial {
+		if err := sendEvent(srv, f, &initial[i]); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case e, ok := <-subscription.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "the stream is closed")
+			}
+			if err := sendEvent(srv, f, &e); err != nil {
+				return err
+			}
+		case <-srv.Context().Done():
+			return nil
+		}
+	}
+}
+
+// sendEvent sends the stream event. The snapshots are sent in DB format taken from the event
+func sendEvent(srv registrypb.SymbolsRegistry_WatchChangesServer, f *filter.Filter, e *types.APIStreamEvent) error {
+	r := registrypb.WatchChangesResponse{
+		Id:       e.ID,
+		Exchange: e.Exchange}
+	switch e.Type {
+	case types.StreamEventTypeChange:
+		r.Event = &registrypb.WatchChangesResponse_Change{
+			Change: registrypb.FromChangeEvent(e.Exchange, e.Change)}
+	case types.StreamEventTypeSnapshot:
+		snapshot := e.StoredSnapshot
+		passed := *snapshot
+		passed.Symbols = make([]types.SymbolInfo, 0, len(snapshot.Symbols))
+		for i := range snapshot.Symbols {
+			if f.MatchSymbolInfo(e.Exchange, &snapshot.Symbols[i]) {
+				passed.Symbols = append(passed.Symbols, snapshot.Symbols[i])
+			}
+		}
+		pb := registrypb.FromExchangeSymbols(e.Exchange, &passed)
+		pb.ContentHash = snapshot.ContentHash()
+		r.Event = &registrypb.WatchChangesResponse_Snapshot{Snapshot: pb}
+	default:
+		return nil
+	}
+	if err := srv.Send(&r); err != nil {
+		glog.Errorf("sendEvent: cannot send event '%d' due to error %s", e.ID, err)
+		return err
+	}
+	return nil
+}
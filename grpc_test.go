@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/etrubenok/make-trades-registry/registrypb"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestGRPCError(t *testing.T) {
+	assert.Equal(t, codes.InvalidArgument, status.Code(GRPCError(NewInvalidParameterError("date", "invalid date"))))
+	assert.Equal(t, codes.NotFound, status.Code(GRPCError(NewNotFoundError("snapshot not found", nil))))
+	assert.Equal(t, codes.Unavailable, status.Code(GRPCError(NewStorageError(errors.New("timeout")))))
+	assert.Equal(t, codes.Unauthenticated, status.Code(GRPCError(NewUnauthorizedError())))
+	assert.Equal(t, codes.Internal, status.Code(GRPCError(errors.New("failure"))))
+	assert.Equal(t, "invalid date", status.Convert(GRPCError(NewInvalidParameterError("date", "invalid date"))).Message())
+}
+
+func TestFilterQuery(t *testing.T) {
+	assert.Empty(t, filterQuery(nil))
+
+	query := filterQuery(&registrypb.SymbolsFilter{
+		Exchanges:   []string{"binance", "bitfinex"},
+		QuoteAssets: []string{"BTC"},
+		Prefix:      "ETH"})
+	assert.Equal(t, "binance@bitfinex", query.Get("exchanges"))
+	assert.Equal(t, "BTC", query.Get("quote"))
+	assert.Equal(t, "ETH", query.Get("prefix"))
+	assert.Equal(t, "", query.Get("symbols"))
+}
+
+// testWatchServer records the responses sent to the WatchChanges stream
+type testWatchServer struct {
+	grpc.ServerStream
+	sent []*registrypb.WatchChangesResponse
+}
+
+func (s *testWatchServer) Send(r *registrypb.WatchChangesResponse) error {
+	s.sent = append(s.sent, r)
+	return nil
+}
+
+func TestSendEventSnapshot(t *testing.T) {
+	f, err := ParseFilterQuery(url.Values{"symbols": []string{"ETHBTC"}})
+	assert.NoError(t, err)
+	snapshot := &types.ExchangeSymbols{
+		SnapshotTime: 1000,
+		Symbols:      []types.SymbolInfo{{Symbol: "BTCUSDT"}, {Symbol: "ETHBTC"}}}
+	srv := &testWatchServer{}
+	assert.NoError(t, sendEvent(srv, f, &types.APIStreamEvent{
+		ID:             7,
+		Type:           types.StreamEventTypeSnapshot,
+		Exchange:       "binance",
+		StoredSnapshot: snapshot}))
+
+	assert.Len(t, srv.sent, 1)
+	assert.Equal(t, int64(7), srv.sent[0].Id)
+	sent := srv.sent[0].GetSnapshot()
+	assert.Equal(t, int64(1000), sent.SnapshotTime)
+	assert.Len(t, sent.Symbols, 1)
+	assert.Equal(t, "ETHBTC", sent.Symbols[0].Symbol)
+	assert.Equal(t, snapshot.ContentHash(), sent.ContentHash)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/health"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// storageCheckTimeout is the time the storage is given to answer the readiness check
+const storageCheckTimeout = 2 * time.Second
+
+var errSessionClosed = errors.New("the Cassandra session is closed")
+
+// stalenessThresholds are the maximum ages of the latest snapshots for the registry to be ready
+var stalenessThresholds *health.Thresholds
+
+// checkStorage runs a trivial query against Cassandra
+func checkStorage() error {
+	if session == nil || session.Closed() {
+		return errSessionClosed
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageCheckTimeout)
+	defer cancel()
+	if err := session.Query("SELECT release_version FROM system.local").WithContext(ctx).Exec(); err != nil {
+		glog.Errorf("checkStorage: Cassandra is not available due to error %s", err)
+		return err
+	}
+	return nil
+}
+
+// GetReadinessReport checks the storage and the freshness of the latest snapshot of every exchange
+func GetReadinessReport(now time.Time) *types.APIReadinessReport {
+	current := symbolsRegistry.Current()
+	snapshots := make(map[string]types.APISnapshotCheckResult)
+	for _, e := range GetAllExchanges() {
+		snapshot, _ := current.Exchange(e)
+		snapshots[e] = health.CheckSnapshot(e, snapshot, stalenessThresholds, now)
+	}
+	return health.NewReadinessReport(health.CheckStorage(checkStorage()), snapshots)
+}
+
+func getHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, types.APICheckResult{Status: types.HealthStatusOK})
+}
+
+func getReadyz(c *gin.Context) {
+	r := GetReadinessReport(time.Now())
+	switch r.Status {
+	case types.HealthStatusFail:
+		glog.Warningf("getReadyz: the registry is not ready: %+v", *r)
+		c.JSON(http.StatusServiceUnavailable, r)
+		return
+	case types.HealthStatusDegraded:
+		glog.Warningf("getReadyz: the registry is degraded: %+v", *r)
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// AnnotateFreshness sets the age of the latest snapshots and whether they are stale
+func AnnotateFreshness(r *types.APIExchangesSymbols, now time.Time) {
+	for i := range r.Exchanges {
+		e := &r.Exchanges[i]
+		e.Freshness = stalenessThresholds.Freshness(e.Exchange, e.SnapshotTime, now)
+	}
+}
@@ -0,0 +1,114 @@
+// Package health checks whether the registry is able to serve up to date symbols
+package health
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Thresholds type contains the maximum age of the latest snapshot of an exchange for the registry to be ready
+type Thresholds struct {
+	Default   time.Duration
+	Exchanges map[string]time.Duration
+}
+
+// ParseThresholds creates the thresholds from the default maximum age and the comma separated list of the overrides
+// in format 'exchange=duration', e.g. 'binance=2m,bitfinex=10m'
+func ParseThresholds(defaultMaxAge time.Duration, overrides string) (*Thresholds, error) {
+	t := Thresholds{
+		Default:   defaultMaxAge,
+		Exchanges: make(map[string]time.Duration)}
+	if overrides == "" {
+		return &t, nil
+	}
+	for _, o := range strings.Split(overrides, ",") {
+		parts := strings.SplitN(strings.TrimSpace(o), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("ParseThresholds: threshold '%s' is not in format 'exchange=duration'", o)
+		}
+		d, err := time.ParseDuration(parts[1])
+		if err != nil {
+			glog.Errorf("ParseThresholds: cannot parse the threshold of exchange '%s' due to error %s", parts[0], err)
+			return nil, err
+		}
+		t.Exchanges[parts[0]] = d
+	}
+	return &t, nil
+}
+
+// MaxAge returns the maximum age of the latest snapshot of the exchange
+func (t *Thresholds) MaxAge(exchange string) time.Duration {
+	if d, ok := t.Exchanges[exchange]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// CheckSnapshot checks that the latest snapshot of the exchange is not older than its threshold. snapshot is nil
+// if there is no snapshot of the exchange yet
+func CheckSnapshot(exchange string, snapshot *types.ExchangeSymbols, t *Thresholds, now time.Time) types.APISnapshotCheckResult {
+	maxAge := t.MaxAge(exchange)
+	r := types.APISnapshotCheckResult{
+		APICheckResult: types.APICheckResult{Status: types.HealthStatusOK},
+		MaxAgeSeconds:  maxAge.Seconds()}
+	if snapshot == nil {
+		r.Status = types.HealthStatusFail
+		r.Message = "no snapshot of the exchange"
+		return r
+	}
+	f := t.Freshness(exchange, snapshot.SnapshotTime, now)
+	r.SnapshotTime = snapshot.SnapshotTime
+	r.AgeSeconds = f.AgeSeconds
+	if f.Stale {
+		r.Status = types.HealthStatusFail
+		r.Message = fmt.Sprintf("the latest snapshot is older than %s", maxAge)
+	}
+	return r
+}
+
+// CheckStorage returns the outcome of the check of the storage which failed with err if it is not nil
+func CheckStorage(err error) types.APICheckResult {
+	if err != nil {
+		return types.APICheckResult{Status: types.HealthStatusFail, Message: err.Error()}
+	}
+	return types.APICheckResult{Status: types.HealthStatusOK}
+}
+
+// NewReadinessReport creates the report from the outcomes of the checks. The registry is not ready if the storage
+// check failed or none of the exchanges has a fresh snapshot, as the history and the fresh exchanges are still served
+// while some exchanges are stale. The registry is degraded then
+func NewReadinessReport(storage types.APICheckResult, snapshots map[string]types.APISnapshotCheckResult) *types.APIReadinessReport {
+	r := types.APIReadinessReport{
+		Status:    storage.Status,
+		Storage:   storage,
+		Snapshots: snapshots}
+	fresh := 0
+	for _, s := range snapshots {
+		if s.Status == types.HealthStatusOK {
+			fresh++
+		}
+	}
+	if r.Status == types.HealthStatusOK && fresh < len(snapshots) {
+		r.Status = types.HealthStatusDegraded
+		if fresh == 0 {
+			r.Status = types.HealthStatusFail
+		}
+	}
+	return &r
+}
+
+// Freshness returns the age of the snapshot taken at snapshotTime in milliseconds since epoch and whether it is stale
+func (t *Thresholds) Freshness(exchange string, snapshotTime int64, now time.Time) *types.APISnapshotFreshness {
+	maxAge := t.MaxAge(exchange)
+	age := now.Sub(time.Unix(0, snapshotTime*int64(time.Millisecond)))
+	f := types.APISnapshotFreshness{
+		AgeSeconds:    age.Seconds(),
+		MaxAgeSeconds: maxAge.Seconds(),
+		Stale:         age > maxAge}
+	return &f
+}
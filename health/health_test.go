@@ -0,0 +1,53 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestParseThresholds(t *testing.T) {
+	th, err := ParseThresholds(5*time.Minute, "binance=2m, bitfinex=10m")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, th.MaxAge("binance"))
+	assert.Equal(t, 10*time.Minute, th.MaxAge("bitfinex"))
+	assert.Equal(t, 5*time.Minute, th.MaxAge("kraken"))
+
+	_, err = ParseThresholds(5*time.Minute, "binance")
+	assert.Error(t, err)
+	_, err = ParseThresholds(5*time.Minute, "binance=soon")
+	assert.Error(t, err)
+}
+
+func TestCheckSnapshot(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 10, 0, 0, time.UTC)
+	th, _ := ParseThresholds(5*time.Minute, "bitfinex=15m")
+	snapshot := &types.ExchangeSymbols{SnapshotTime: now.Add(-10*time.Minute).UnixNano() / int64(time.Millisecond)}
+
+	r := CheckSnapshot("binance", snapshot, th, now)
+	assert.Equal(t, types.HealthStatusFail, r.Status)
+	assert.Equal(t, float64(600), r.AgeSeconds)
+	assert.Equal(t, float64(300), r.MaxAgeSeconds)
+
+	assert.Equal(t, types.HealthStatusOK, CheckSnapshot("bitfinex", snapshot, th, now).Status)
+	assert.Equal(t, types.HealthStatusFail, CheckSnapshot("binance", nil, th, now).Status)
+}
+
+func TestNewReadinessReport(t *testing.T) {
+	ok := types.APISnapshotCheckResult{APICheckResult: types.APICheckResult{Status: types.HealthStatusOK}}
+	stale := types.APISnapshotCheckResult{APICheckResult: types.APICheckResult{Status: types.HealthStatusFail}}
+
+	assert.Equal(t, types.HealthStatusOK, NewReadinessReport(CheckStorage(nil),
+		map[string]types.APISnapshotCheckResult{"binance": ok}).Status)
+	assert.Equal(t, types.HealthStatusDegraded, NewReadinessReport(CheckStorage(nil),
+		map[string]types.APISnapshotCheckResult{"binance": ok, "bitfinex": stale}).Status)
+	assert.Equal(t, types.HealthStatusFail, NewReadinessReport(CheckStorage(nil),
+		map[string]types.APISnapshotCheckResult{"binance": stale, "bitfinex": stale}).Status)
+	r := NewReadinessReport(CheckStorage(errors.New("no hosts available")), map[string]types.APISnapshotCheckResult{"binance": ok})
+	assert.Equal(t, types.HealthStatusFail, r.Status)
+	assert.Equal(t, "no hosts available", r.Storage.Message)
+}
@@ -0,0 +1,175 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/metrics"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Alert type is the notification of the latest snapshot of an exchange becoming stale or fresh again
+type Alert struct {
+	Exchange      string  `json:"exchange"`
+	Stale         bool    `json:"stale"`
+	SnapshotTime  int64   `json:"snapshot_time,omitempty"`
+	AgeSeconds    float64 `json:"age_seconds"`
+	MaxAgeSeconds float64 `json:"max_age_seconds"`
+	Time          int64   `json:"time"`
+}
+
+// Alerter is an interface of the destinations of the alerts
+type Alerter interface {
+	Alert(a *Alert) error
+}
+
+// LogAlerter is an implementation of Alerter interface writing the alerts to the log
+type LogAlerter struct{}
+
+// NewLogAlerter instantiates object of Alerter interface (LogAlerter class)
+func NewLogAlerter() Alerter {
+	return &LogAlerter{}
+}
+
+// Alert writes the alert to the log
+func (l *LogAlerter) Alert(a *Alert) error {
+	if a.Stale {
+		glog.Warningf("LogAlerter.Alert: the latest snapshot of exchange '%s' is stale: %.0fs old, the maximum age is %.0fs",
+			a.Exchange, a.AgeSeconds, a.MaxAgeSeconds)
+	} else {
+		glog.Infof("LogAlerter.Alert: the latest snapshot of exchange '%s' is fresh again", a.Exchange)
+	}
+	return nil
+}
+
+// MetricAlerter is an implementation of Alerter interface exporting the alerts as metrics
+type MetricAlerter struct{}
+
+// NewMetricAlerter instantiates object of Alerter interface (MetricAlerter class). The exchanges start as not stale
+func NewMetricAlerter(exchanges []string) Alerter {
+	for _, e := range exchanges {
+		metrics.StaleSnapshots.WithLabelValues(e).Set(0)
+	}
+	return &MetricAlerter{}
+}
+
+// Alert sets the stale gauge of the exchange and counts the alerts on it becoming stale
+func (m *MetricAlerter) Alert(a *Alert) error {
+	if a.Stale {
+		metrics.StaleSnapshots.WithLabelValues(a.Exchange).Set(1)
+		metrics.StaleAlerts.WithLabelValues(a.Exchange).Inc()
+	} else {
+		metrics.StaleSnapshots.WithLabelValues(a.Exchange).Set(0)
+	}
+	return nil
+}
+
+// WebhookAlerter is an implementation of Alerter interface posting the alerts in JSON format to a URL
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlerter instantiates object of Alerter interface (WebhookAlerter class)
+func NewWebhookAlerter(url string) Alerter {
+	w := WebhookAlerter{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second}}
+	return &w
+}
+
+// Alert posts the alert to the webhook
+func (w *WebhookAlerter) Alert(a *Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		glog.Errorf("WebhookAlerter.Alert: cannot marshal the alert due to error %s", err)
+		return err
+	}
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		glog.Errorf("WebhookAlerter.Alert: cannot post the alert to '%s' due to error %s", w.url, err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("WebhookAlerter.Alert: webhook '%s' responded with status %d", w.url, resp.StatusCode)
+		glog.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Monitor checks the freshness of the latest snapshots and alerts when an exchange becomes stale or fresh again
+type Monitor struct {
+	exchanges  []string
+	thresholds *Thresholds
+	snapshots  func() map[string]*types.ExchangeSymbols
+	alerters   []Alerter
+	now        func() time.Time
+
+	mutex sync.Mutex
+	stale map[string]bool
+}
+
+// NewMonitor instantiates Monitor object. snapshots returns the latest snapshots keyed by the exchange name
+func NewMonitor(exchanges []string, thresholds *Thresholds, snapshots func() map[string]*types.ExchangeSymbols, alerters ...Alerter) *Monitor {
+	m := Monitor{
+		exchanges:  exchanges,
+		thresholds: thresholds,
+		snapshots:  snapshots,
+		alerters:   alerters,
+		now:        time.Now,
+		stale:      make(map[string]bool)}
+	return &m
+}
+
+// Check checks the latest snapshot of every exchange and sends the alerts on the exchanges which state changed.
+// An exchange without a snapshot is stale
+func (m *Monitor) Check() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := m.now()
+	snapshots := m.snapshots()
+	for _, e := range m.exchanges {
+		a := Alert{
+			Exchange:      e,
+			Stale:         true,
+			MaxAgeSeconds: m.thresholds.MaxAge(e).Seconds(),
+			Time:          now.UnixNano() / int64(time.Millisecond)}
+		if s, ok := snapshots[e]; ok {
+			f := m.thresholds.Freshness(e, s.SnapshotTime, now)
+			a.Stale = f.Stale
+			a.SnapshotTime = s.SnapshotTime
+			a.AgeSeconds = f.AgeSeconds
+		}
+		if a.Stale == m.stale[e] {
+			continue
+		}
+		m.stale[e] = a.Stale
+		for _, alerter := range m.alerters {
+			if err := alerter.Alert(&a); err != nil {
+				glog.Errorf("Monitor.Check: cannot send the alert on exchange '%s' due to error %s", e, err)
+			}
+		}
+	}
+}
+
+// Run checks the snapshots every interval until stop is closed
+func (m *Monitor) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.Check()
+		case <-stop:
+			return
+		}
+	}
+}
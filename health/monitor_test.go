@@ -0,0 +1,79 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/metrics"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+type testAlerter struct {
+	alerts []Alert
+}
+
+func (a *testAlerter) Alert(alert *Alert) error {
+	a.alerts = append(a.alerts, *alert)
+	return nil
+}
+
+func TestMonitorCheck(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 10, 0, 0, time.UTC)
+	th, _ := ParseThresholds(5*time.Minute, "")
+	snapshots := map[string]*types.ExchangeSymbols{
+		"binance": {SnapshotTime: now.Add(-time.Minute).UnixNano() / int64(time.Millisecond)}}
+	alerter := &testAlerter{}
+	m := NewMonitor([]string{"binance", "bitfinex"}, th, func() map[string]*types.ExchangeSymbols { return snapshots }, alerter)
+	m.now = func() time.Time { return now }
+
+	// Only the exchange without a snapshot is reported
+	m.Check()
+	assert.Equal(t, 1, len(alerter.alerts))
+	assert.Equal(t, "bitfinex", alerter.alerts[0].Exchange)
+	assert.True(t, alerter.alerts[0].Stale)
+
+	// The state changes are reported once
+	now = now.Add(10 * time.Minute)
+	m.Check()
+	m.Check()
+	assert.Equal(t, 2, len(alerter.alerts))
+	assert.Equal(t, "binance", alerter.alerts[1].Exchange)
+	assert.Equal(t, float64(660), alerter.alerts[1].AgeSeconds)
+
+	snapshots["binance"] = &types.ExchangeSymbols{SnapshotTime: now.UnixNano() / int64(time.Millisecond)}
+	m.Check()
+	assert.Equal(t, 3, len(alerter.alerts))
+	assert.False(t, alerter.alerts[2].Stale)
+}
+
+func TestMetricAlerter(t *testing.T) {
+	alertsBefore := testutil.ToFloat64(metrics.StaleAlerts.WithLabelValues("kraken"))
+	a := NewMetricAlerter([]string{"kraken"})
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.StaleSnapshots.WithLabelValues("kraken")))
+	assert.NoError(t, a.Alert(&Alert{Exchange: "kraken", Stale: true}))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.StaleSnapshots.WithLabelValues("kraken")))
+	assert.Equal(t, alertsBefore+1, testutil.ToFloat64(metrics.StaleAlerts.WithLabelValues("kraken")))
+}
+
+func TestWebhookAlerter(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewWebhookAlerter(server.URL).Alert(&Alert{Exchange: "binance", Stale: true, AgeSeconds: 600}))
+	assert.Equal(t, Alert{Exchange: "binance", Stale: true, AgeSeconds: 600}, received)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, NewWebhookAlerter(failing.URL).Alert(&Alert{Exchange: "binance"}))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// LookupInstrument finds the native symbol by the canonical identifier if it is given or the canonical identifier
+// by the native symbol otherwise
+func LookupInstrument(index *types.InstrumentIndex, native string, canonical string) (string, string, bool) {
+	if canonical != "" {
+		native, ok := index.Native(canonical)
+		if !ok {
+			return "", "", false
+		}
+		canonical, _ = index.Canonical(native)
+		return native, canonical, true
+	}
+	canonical, ok := index.Canonical(native)
+	return native, canonical, ok
+}
+
+func getInstrument(c *gin.Context) {
+	native := c.Request.URL.Query().Get("native")
+	canonical := c.Request.URL.Query().Get("canonical")
+
+	var exchange, symbol string
+	var err error
+	switch {
+	case canonical != "":
+		var id types.InstrumentID
+		id, err = types.ParseInstrumentID(canonical)
+		if err != nil {
+			glog.Errorf("getInstrument: %s", err)
+			respondError(c, NewInvalidParameterError("canonical", "'canonical' must be in format 'exchange:type:BASE/QUOTE'"))
+			return
+		}
+		exchange = id.Exchange
+	case native != "":
+		exchange, symbol, err = ParseSymbolID(native)
+		if err != nil {
+			glog.Errorf("getInstrument: %s", err)
+			respondError(c, NewInvalidParameterError("native", "'native' must be in format 'exchange-SYMBOL'"))
+			return
+		}
+	default:
+		respondError(c, NewInvalidParameterError("native", "either 'native' or 'canonical' must be given"))
+		return
+	}
+
+	exchangeIDs, err := GetExchangeIDs([]string{exchange})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshots, err := q.Load(snapshotsCache, exchangeIDs)
+	if err != nil {
+		glog.Errorf("getInstrument: cannot load the snapshot of exchange '%s' due to error %s", exchange, err)
+		respondError(c, err)
+		return
+	}
+
+	index := types.NewInstrumentIndex(exchange, &snapshots.Exchanges[0])
+	symbol, canonical, ok := LookupInstrument(index, symbol, canonical)
+	if !ok {
+		respondError(c, NewNotFoundError(fmt.Sprintf("instrument not found on exchange '%s'", exchange), nil))
+		return
+	}
+	c.JSON(http.StatusOK, types.APIInstrumentLookup{
+		Exchange:     exchange,
+		Symbol:       exchange + "-" + symbol,
+		NativeSymbol: symbol,
+		CanonicalID:  canonical})
+}
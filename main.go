@@ -3,34 +3,77 @@ package main
 import (
 	"context"
 	"flag"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/etrubenok/make-trades-registry/archive"
+	"github.com/etrubenok/make-trades-registry/assets"
+	"github.com/etrubenok/make-trades-registry/core"
+	"github.com/etrubenok/make-trades-registry/db"
 	"github.com/etrubenok/make-trades-registry/fetchers"
+	"github.com/etrubenok/make-trades-registry/filter"
+	"github.com/etrubenok/make-trades-registry/formats"
+	"github.com/etrubenok/make-trades-registry/health"
+	"github.com/etrubenok/make-trades-registry/metrics"
+	"github.com/etrubenok/make-trades-registry/publishers"
+	"github.com/etrubenok/make-trades-registry/registrypb"
+	"github.com/etrubenok/make-trades-registry/stream"
 	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-registry/validation"
 	"github.com/etrubenok/make-trades-types/registry"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/glog"
 
 	"github.com/gocql/gocql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
 )
 
 var session *gocql.Session
 
+// quarantine keeps the fetched snapshots rejected by the validation
+var quarantine validation.Quarantine
+
+// snapshotsCache is the DBLoader serving the requests, it keeps the latest snapshots and the past lookups in memory
+var snapshotsCache *db.CachingDBLoader
+
 var exchanges = []string{"binance", "bitfinex"}
 
-// GetPreviousDate returns year, month, day of the previous day from the currentTime
-func GetPreviousDate(currentTime time.Time) (int, int, int) {
-	t := currentTime.AddDate(0, 0, -1).UnixNano() / int64(time.Millisecond)
-	year, month, day := fetchers.GetYearMonthDay(t)
-	glog.Infof("GetPreviousDate: previous day (year: %d, month: %d, day: %d)",
-		year, month, day)
-	return year, month, day
-}
+// symbolsRegistry holds the latest symbols of all the exchanges in memory
+var symbolsRegistry *core.Registry
+
+var (
+	cassandraHosts   = flag.String("cassandra-hosts", "cassandra", "comma separated list of Cassandra hosts")
+	lookBackDays     = flag.Int("look-back-days", 7, "number of days before the requested date searched for a snapshot")
+	publisherName    = flag.String("publisher", "none", "publisher of snapshots and change events: none, kafka, nats or memory")
+	kafkaBrokers     = flag.String("kafka-brokers", "localhost:9092", "comma separated list of Kafka brokers")
+	kafkaTopic       = flag.String("kafka-topic", "make-trades-registry-symbols", "Kafka topic for snapshots and change events")
+	natsURL          = flag.String("nats-url", "nats://localhost:4222", "NATS server URL")
+	natsSubject      = flag.String("nats-subject", "registry.symbols", "NATS subject prefix for snapshots and change events")
+	allowedOrigins   = flag.String("allowed-origins", "", "comma separated origins allowed to open the WebSocket streams besides the host of the service, e.g. 'https://app.example.com'")
+	streamBuffer     = flag.Int("stream-buffer", 10000, "number of recent change events kept for the stream clients resuming from an event id")
+	assetAliasesFile = flag.String("asset-aliases", "", "JSON file with the versioned asset aliases table, the built-in table is used if not given")
+	adminToken       = flag.String("admin-token", "", "bearer token required by the admin API, the admin API is disabled if not given")
+	grpcAddress      = flag.String("grpc-address", ":9090", "address the gRPC API listens on")
+	cacheSize        = flag.Int("cache-size", 1000, "number of the past snapshot lookups cached in memory")
+	maxSnapshotAge   = flag.Duration("max-snapshot-age", 5*time.Minute, "maximum age of the latest snapshot of an exchange for the registry to be ready")
+	exchangesMaxAge  = flag.String("exchange-max-snapshot-age", "", "comma separated maximum ages of the latest snapshots overriding max-snapshot-age, e.g. 'binance=2m,bitfinex=10m'")
+	staleWebhookURL  = flag.String("stale-webhook-url", "", "URL the alerts on the exchanges becoming stale are posted to, no webhook is called if not given")
+	staleCheckPeriod = flag.Duration("stale-check-interval", 30*time.Second, "interval of the checks of the freshness of the latest snapshots")
+	minSymbols       = flag.Int("min-symbols", validation.DefaultRules.MinSymbols, "minimum number of symbols in a snapshot, the smaller snapshots are quarantined")
+	maxRemoved       = flag.Float64("max-removed-percent", validation.DefaultRules.MaxRemovedPercent, "maximum percentage of the symbols removed since the previous snapshot, the snapshots removing more are quarantined")
+	confirmRemovals  = flag.Int("confirm-removals", validation.DefaultRules.ConfirmRemovals, "number of consecutive snapshots agreeing with each other after which the removal of more than max-removed-percent symbols is accepted, 0 never accepts it")
+	quarantineDir    = flag.String("quarantine-dir", "", "directory the rejected snapshots are written to, the recent ones are kept in memory if not given")
+	archiveDir       = flag.String("archive-dir", "", "directory the raw responses of the exchanges are archived in for the replays, nothing is archived if not given")
+	seedFile         = flag.String("seed-file", "", "JSON file with the snapshots the registry starts with, the latest snapshots in Cassandra are used if not given")
+)
 
 // GetYearMonthDay returns year, month and day for a given date in format 'yyyy-mm-dd'
 func GetYearMonthDay(yyyymmddDate string) (int, int, int, error) {
@@ -42,37 +85,100 @@ func GetYearMonthDay(yyyymmddDate string) (int, int, int, error) {
 	return t.Year(), int(t.Month()), t.Day(), nil
 }
 
-// GetSymbolsSnapshot gets symbols snapshot on a date
-func GetSymbolsSnapshot(exchanges []string, getDate func() (int, int, int, error)) (*types.APIExchangesSymbols, error) {
-	exchnageIDs := make([]int, 0)
+// GetExchangeIDs returns the ids of the exchanges
+func GetExchangeIDs(exchanges []string) ([]int, error) {
+	exchangeIDs := make([]int, 0)
 	for _, e := range exchanges {
 		exchangeID, err := registry.GetExchangeID(e)
 		if err != nil {
-			glog.Errorf("GetLatestSymbolsSnapshot: cannot get exchange id for exchange '%s' due to error %s", e, err)
-			return nil, err
+			glog.Errorf("GetExchangeIDs: cannot get exchange id for exchange '%s' due to error %s", e, err)
+			return nil, NewUnknownExchangeError(e)
 		}
-		exchnageIDs = append(exchnageIDs, exchangeID)
+		exchangeIDs = append(exchangeIDs, exchangeID)
 	}
+	return exchangeIDs, nil
+}
 
-	fetcher := fetchers.NewBitfinexFetcher()
-	exchangeSymbols, err := fetcher.FetchSymbols()
-	if err != nil {
-		glog.Errorf("GetLatestSymbolsSnapshot: LoadSymbolsSnapshots failed to load the symbols for exchnages %v due to error %s", exchanges, err)
-		return nil, err
+// SnapshotQuery type contains the point in time of the requested snapshots: either the exact time
+// or the day which latest snapshots are requested
+type SnapshotQuery struct {
+	At    *time.Time
+	Year  int
+	Month int
+	Day   int
+	// Latest is set when neither the time nor the day is requested, the in-memory registry serves such queries
+	Latest bool
+}
+
+// NewSnapshotQuery creates the snapshot query for the time if it is not nil, otherwise for the date in format 'yyyy-mm-dd'
+// if it is not empty, otherwise for the latest snapshots
+func NewSnapshotQuery(at *time.Time, date string) (*SnapshotQuery, error) {
+	if at != nil {
+		return &SnapshotQuery{At: at}, nil
+	}
+	if date != "" {
+		year, month, day, err := GetYearMonthDay(date)
+		if err != nil {
+			glog.Errorf("NewSnapshotQuery: cannot get year, month and day from string 'yyyy-mm-dd'(%s) due to error '%s'", date, err)
+			return nil, NewInvalidParameterError("date", "'date' must be in format 'yyyy-mm-dd'")
+		}
+		return &SnapshotQuery{Year: year, Month: month, Day: day}, nil
+	}
+	year, month, day := fetchers.GetYearMonthDay(time.Now().UnixNano() / int64(time.Millisecond))
+	return &SnapshotQuery{Year: year, Month: month, Day: day, Latest: true}, nil
+}
+
+// ParseSnapshotQuery creates the snapshot query from the 'at' or 'date' query parameters. The latest snapshots are requested by default
+func ParseSnapshotQuery(c *gin.Context) (*SnapshotQuery, error) {
+	if at := c.Request.URL.Query().Get("at"); at != "" {
+		atTime, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			glog.Errorf("ParseSnapshotQuery: cannot parse time '%s' in RFC3339 format due to error '%s'", at, err)
+			return nil, NewInvalidParameterError("at", "'at' must be a time in RFC3339 format")
+		}
+		return NewSnapshotQuery(&atTime, "")
 	}
+	return NewSnapshotQuery(nil, c.Request.URL.Query().Get("date"))
+}
 
-	exchangesSymbols := types.ExchangesSymbols{
-		Exchanges: make([]types.ExchangeSymbols, 1)}
-	exchangesSymbols.Exchanges[0] = *exchangeSymbols
+// Load loads the snapshots of the exchanges requested by the query
+func (q *SnapshotQuery) Load(l db.DBLoader, exchangeIDs []int) (*types.ExchangesSymbols, error) {
+	if q.Latest && symbolsRegistry != nil {
+		if exchangesSymbols, ok := symbolsRegistry.Current().ExchangesByID(exchangeIDs); ok {
+			return exchangesSymbols, nil
+		}
+	}
+	var exchangesSymbols *types.ExchangesSymbols
+	var err error
+	if q.At != nil {
+		exchangesSymbols, err = l.LoadSymbolsSnapshotsAt(exchangeIDs, *q.At)
+	} else {
+		exchangesSymbols, err = l.LoadSymbolsSnapshots(exchangeIDs, func() (int, int, int, error) {
+			return q.Year, q.Month, q.Day, nil
+		})
+	}
+	if err != nil {
+		glog.Errorf("SnapshotQuery.Load: cannot load the symbols for exchange ids %v due to error %s", exchangeIDs, err)
+		return nil, NewStorageError(err)
+	}
+	return exchangesSymbols, nil
+}
 
-	// l := NewDBLoader(session)
-	// exchangesSymbols, err := l.LoadSymbolsSnapshots(exchnageIDs, getDate)
+// GetSymbolsSnapshot gets symbols snapshot requested by the query
+func GetSymbolsSnapshot(exchanges []string, q *SnapshotQuery) (*types.APIExchangesSymbols, error) {
+	exchangeIDs, err := GetExchangeIDs(exchanges)
+	if err != nil {
+		glog.Errorf("GetSymbolsSnapshot: cannot get exchange ids for exchanges %v due to error %s", exchanges, err)
+		return nil, err
+	}
 
-	// if err != nil {
-	// 	glog.Errorf("GetLatestSymbolsSnapshot: LoadSymbolsSnapshots failed to load the symbols for exchnages %v due to error %s", exchanges, err)
-	// 	return nil, err
-	// }
-	resp, err := types.ConvertExchangeSymbolsToAPIResponse(&exchangesSymbols)
+	l := snapshotsCache
+	exchangesSymbols, err := q.Load(l, exchangeIDs)
+	if err != nil {
+		glog.Errorf("GetSymbolsSnapshot: failed to load the symbols for exchnages %v due to error %s", exchanges, err)
+		return nil, err
+	}
+	resp, err := types.ConvertExchangeSymbolsToAPIResponse(exchangesSymbols)
 	if err != nil {
 		glog.Errorf("ConvertExchangeSymbolsToAPIResponse: cannot convert to API response due to error %s", err)
 		return nil, err
@@ -80,59 +186,233 @@ func GetSymbolsSnapshot(exchanges []string, getDate func() (int, int, int, error
 	return resp, nil
 }
 
+// LoadExchangesSnapshots loads the snapshots requested by the query keyed by the exchange name
+func LoadExchangesSnapshots(exchanges []string, q *SnapshotQuery) (map[string]*types.ExchangeSymbols, error) {
+	exchangeIDs, err := GetExchangeIDs(exchanges)
+	if err != nil {
+		glog.Errorf("LoadExchangesSnapshots: cannot get exchange ids for exchanges %v due to error %s", exchanges, err)
+		return nil, err
+	}
+	exchangesSymbols, err := q.Load(snapshotsCache, exchangeIDs)
+	if err != nil {
+		glog.Errorf("LoadExchangesSnapshots: failed to load the symbols for exchanges %v due to error %s", exchanges, err)
+		return nil, err
+	}
+	snapshots := make(map[string]*types.ExchangeSymbols, len(exchanges))
+	for i, e := range exchanges {
+		snapshots[e] = &exchangesSymbols.Exchanges[i]
+	}
+	return snapshots, nil
+}
+
+// GetExchangesParam returns the exchanges listed in the 'exchanges' query parameter or all the supported exchanges if it is not given
+func GetExchangesParam(c *gin.Context) []string {
+	exchangesFilter := c.Request.URL.Query().Get("exchanges")
+	if exchangesFilter == "" {
+		return GetAllExchanges()
+	}
+	exchanges := make([]string, 0)
+	listed := make(map[string]bool)
+	for _, e := range strings.Split(exchangesFilter, filter.Separator) {
+		if !listed[e] {
+			exchanges = append(exchanges, e)
+			listed[e] = true
+		}
+	}
+	return exchanges
+}
+
 // GetAllExchanges returns all the supported exchanges
 func GetAllExchanges() []string {
 	return exchanges
 }
 
+// ParseFilter creates the symbols filter from the query parameters
+func ParseFilter(c *gin.Context) (*filter.Filter, error) {
+	return ParseFilterQuery(c.Request.URL.Query())
+}
+
+// ParseFilterQuery creates the symbols filter from the query parameters reporting the malformed ones as invalid parameters
+func ParseFilterQuery(query url.Values) (*filter.Filter, error) {
+	f, err := filter.ParseQuery(query)
+	if err != nil {
+		glog.Errorf("ParseFilterQuery: invalid filter due to error '%s'", err)
+		if paramErr, ok := err.(*filter.ParameterError); ok {
+			return nil, NewInvalidParameterError(paramErr.Parameter, paramErr.Message)
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
 func getSymbols(c *gin.Context) {
-	filter := c.Request.URL.Query().Get("exchanges")
-	exchanges := []string{}
-	if filter != "" {
-		exchanges = strings.Split(filter, "@")
+	symbolsFilter, err := ParseFilter(c)
+	if err != nil {
+		respondError(c, err)
+		return
 	}
-	if filter == "" {
-		exchanges = GetAllExchanges()
+	format, err := NegotiateFormat(c)
+	if err != nil {
+		respondError(c, err)
+		return
 	}
 
-	date := c.Request.URL.Query().Get("date")
-	var symbolsSnapshot *types.APIExchangesSymbols
-	var err error
-	if date != "" {
-		symbolsSnapshot, err = GetSymbolsSnapshot(exchanges, func() (int, int, int, error) {
-			year, month, day, err := GetYearMonthDay(date)
-			if err != nil {
-				glog.Errorf("getSymbols: cannot get year, month and day from string 'yyyy-mm-dd'(%s) due to error '%s'",
-					date, err)
-				return 0, 0, 0, nil
-			}
-			return year, month, day, nil
-		})
-	} else {
-		symbolsSnapshot, err = GetSymbolsSnapshot(exchanges, func() (int, int, int, error) {
-			t := time.Now().UnixNano() / int64(time.Millisecond)
-			year, month, day := fetchers.GetYearMonthDay(t)
-			return year, month, day, nil
-		})
+	exchanges := GetExchangesParam(c)
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
 	}
+	symbolsSnapshot, err := GetSymbolsSnapshot(exchanges, q)
 	if err != nil {
-		glog.Errorf("getSymbols: cannot get symbols for exchanges '%v' and date '%s' due to error '%s'",
+		glog.Errorf("getSymbols: cannot get symbols for exchanges '%v' due to error '%s'",
 			exchanges,
-			date,
 			err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "server error"})
+		respondError(c, err)
+		return
+	}
+	if q.Latest {
+		AnnotateFreshness(symbolsSnapshot, time.Now())
+	}
+	if NewCacheValidators(format, symbolsSnapshot, q.CacheControl(time.Now())).CheckNotModified(c) {
 		return
 	}
-	c.JSON(http.StatusOK, symbolsSnapshot)
+	filtered := symbolsFilter.Apply(symbolsSnapshot)
+	if format != formats.JSON {
+		respondSymbols(c, format, filtered, symbolsFilter.Fields)
+		return
+	}
+	c.JSON(http.StatusOK, symbolsFilter.Project(filtered))
+}
+
+// latestSnapshots returns the latest snapshots held by the in-memory registry keyed by the exchange name
+func latestSnapshots() map[string]*types.ExchangeSymbols {
+	current := symbolsRegistry.Current()
+	r := make(map[string]*types.ExchangeSymbols)
+	for _, e := range GetAllExchanges() {
+		if s, ok := current.Exchange(e); ok {
+			r[e] = s
+		}
+	}
+	return r
 }
 
 func main() {
 	flag.Parse()
+	if *lookBackDays < 0 {
+		glog.Fatalf("main: the number of look back days must not be negative, got %d", *lookBackDays)
+	}
+
+	cluster := gocql.NewCluster(strings.Split(*cassandraHosts, ",")...)
+	cluster.Consistency = gocql.Quorum
+	var err error
+	session, err = cluster.CreateSession()
+	if err != nil {
+		glog.Fatalf("main: cannot connect to Cassandra hosts '%s' due to error %s", *cassandraHosts, err)
+	}
+	defer session.Close()
+
+	stalenessThresholds, err = health.ParseThresholds(*maxSnapshotAge, *exchangesMaxAge)
+	if err != nil {
+		glog.Fatalf("main: cannot parse the maximum ages of the snapshots '%s' due to error %s", *exchangesMaxAge, err)
+	}
+
+	if *assetAliasesFile != "" {
+		t, err := assets.LoadFile(*assetAliasesFile)
+		if err != nil {
+			glog.Fatalf("main: cannot load asset aliases from file '%s' due to error %s", *assetAliasesFile, err)
+		}
+		assets.Default = assets.NewRegistry(t)
+	}
+
+	if *archiveDir != "" {
+		store, err := archive.NewFileStore(*archiveDir)
+		if err != nil {
+			glog.Fatalf("main: cannot create the archive in directory '%s' due to error %s", *archiveDir, err)
+		}
+		archive.Default = archive.NewArchive(store)
+	}
+
+	publisher, err := publishers.PublisherFactory(*publisherName, &publishers.Config{
+		KafkaBrokers: publishers.ParseBrokers(*kafkaBrokers),
+		KafkaTopic:   *kafkaTopic,
+		NATSURL:      *natsURL,
+		NATSSubject:  *natsSubject})
+	if err != nil {
+		glog.Fatalf("main: cannot create publisher '%s' due to error %s", *publisherName, err)
+	}
+	hub = stream.NewHub(*streamBuffer)
+	publisher = publishers.NewMultiPublisher(publisher, hub)
+	defer publisher.Close()
+
+	snapshotsCache = db.NewCachingDBLoader(db.NewMeteredDBLoader(db.NewDBLoader(session, *lookBackDays)), *cacheSize)
+	symbolsRegistry = core.NewRegistry(GetAllExchanges())
+	seed := core.NewDBSeed(snapshotsCache)
+	if *seedFile != "" {
+		seed = core.NewFileSeed(*seedFile)
+	}
+	if err := symbolsRegistry.Seed(seed); err != nil {
+		glog.Warningf("main: cannot seed the registry due to error %s, it is filled by the first fetch", err)
+	}
+	// The latest snapshots of the registry are also the latest ones of the cache until the first fetch
+	if allExchangeIDs, err := GetExchangeIDs(GetAllExchanges()); err == nil {
+		if seeded, ok := symbolsRegistry.Current().ExchangesByID(allExchangeIDs); ok {
+			snapshotsCache.Update(seeded)
+		}
+	}
+	quarantine = validation.NewMemoryQuarantine(100)
+	if *quarantineDir != "" {
+		quarantine, err = validation.NewFileQuarantine(*quarantineDir)
+		if err != nil {
+			glog.Fatalf("main: cannot create the quarantine in directory '%s' due to error %s", *quarantineDir, err)
+		}
+	}
+	guard := validation.NewGuard(validation.Rules{MinSymbols: *minSymbols, MaxRemovedPercent: *maxRemoved, ConfirmRemovals: *confirmRemovals},
+		quarantine, symbolsRegistry.Current().Symbols())
+
+	results := make(chan types.ExchangesSymbols)
+	// The processor compares the first fetched snapshots with the seeded ones to publish the changes made since
+	processor := NewProcessor(db.NewMeteredDBImporter(db.NewDBImporter(session)), publisher, snapshotsCache)
+	processor.Seed(symbolsRegistry.Current().Symbols())
+	stopRegistry := make(chan struct{})
+	defer close(stopRegistry)
+	symbolsRegistry.Run(results, guard, stopRegistry)
+	go processor.Run(results)
 
 	gin.SetMode(gin.ReleaseMode)
 
+	prometheus.MustRegister(metrics.NewSnapshotsCollector(latestSnapshots))
+
+	alerters := []health.Alerter{health.NewLogAlerter(), health.NewMetricAlerter(GetAllExchanges())}
+	if *staleWebhookURL != "" {
+		alerters = append(alerters, health.NewWebhookAlerter(*staleWebhookURL))
+	}
+	stopMonitor := make(chan struct{})
+	defer close(stopMonitor)
+	go health.NewMonitor(GetAllExchanges(), stalenessThresholds, latestSnapshots, alerters...).Run(*staleCheckPeriod, stopMonitor)
+
 	r := gin.Default()
+	r.Use(metrics.Middleware())
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", getHealthz)
+	r.GET("/readyz", getReadyz)
 	r.GET("/symbols", getSymbols)
+	r.GET("/symbols/:id", getSymbol)
+	r.GET("/instruments", getInstrument)
+	r.GET("/markets/common", getCommonMarkets)
+	r.GET("/assets", getAssets)
+	r.GET("/assets/:asset", getAsset)
+	r.GET("/conversions", getConversionRoutes)
+	r.GET("/stream/symbols", streamSymbolsSSE)
+	r.GET("/stream/symbols/ws", streamSymbolsWebSocket)
+	r.GET("/exchanges/:exchange/snapshots", getExchangeSnapshots)
+	r.GET("/exchanges/:exchange/snapshots/:time", getExchangeSnapshot)
+
+	admin := r.Group("/admin", RequireAdminToken(*adminToken))
+	admin.GET("/assets/aliases", getAssetAliases)
+	admin.PUT("/assets/aliases", putAssetAliases)
+	admin.GET("/cache/stats", getCacheStats)
+	admin.GET("/quarantine", getQuarantine)
 
 	srv := &http.Server{
 		Addr:    ":8080",
@@ -145,6 +425,19 @@ func main() {
 		}
 	}()
 
+	grpcServer := grpc.NewServer()
+	registrypb.RegisterSymbolsRegistryServer(grpcServer, NewRegistryServer())
+	go func() {
+		listener, err := net.Listen("tcp", *grpcAddress)
+		if err != nil {
+			glog.Errorf("grpc listen: %s", err)
+			return
+		}
+		if err := grpcServer.Serve(listener); err != nil {
+			glog.Errorf("grpc serve: %s", err)
+		}
+	}()
+
 	sigchan := make(chan os.Signal, 1)
 	signal.Notify(sigchan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -162,5 +455,16 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		glog.Errorf("Server Shutdown: %s", err)
 	}
+	// The watch streams never end by themselves, so they are cut when the graceful stop takes too long
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
 	glog.Infof("Server exiting")
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/filter"
+	"github.com/etrubenok/make-trades-registry/markets"
+)
+
+// GetQuotesParam returns the set of the upper case quote assets listed in the 'quote' query parameter
+func GetQuotesParam(c *gin.Context) map[string]bool {
+	quotes := make(map[string]bool)
+	if quote := c.Request.URL.Query().Get("quote"); quote != "" {
+		for _, q := range strings.Split(quote, filter.Separator) {
+			quotes[strings.ToUpper(q)] = true
+		}
+	}
+	return quotes
+}
+
+// GetMinVenuesParam returns the 'min_venues' query parameter or the number of exchanges if it is not given
+func GetMinVenuesParam(c *gin.Context, exchangesCount int) (int, error) {
+	value := c.Request.URL.Query().Get("min_venues")
+	if value == "" {
+		return exchangesCount, nil
+	}
+	minVenues, err := strconv.Atoi(value)
+	if err != nil || minVenues <= 0 || minVenues > exchangesCount {
+		return 0, NewInvalidParameterError("min_venues",
+			fmt.Sprintf("'min_venues' must be an integer between 1 and the number of exchanges (%d)", exchangesCount))
+	}
+	return minVenues, nil
+}
+
+func getCommonMarkets(c *gin.Context) {
+	exchanges := GetExchangesParam(c)
+	minVenues, err := GetMinVenuesParam(c, len(exchanges))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshots, err := LoadExchangesSnapshots(exchanges, q)
+	if err != nil {
+		glog.Errorf("getCommonMarkets: cannot load the snapshots of exchanges %v due to error %s", exchanges, err)
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, markets.CommonMarkets(snapshots, GetQuotesParam(c), minVenues))
+}
+
+func getAssets(c *gin.Context) {
+	exchanges := GetExchangesParam(c)
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshots, err := LoadExchangesSnapshots(exchanges, q)
+	if err != nil {
+		glog.Errorf("getAssets: cannot load the snapshots of exchanges %v due to error %s", exchanges, err)
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, markets.Assets(snapshots))
+}
+
+func getAsset(c *gin.Context) {
+	asset := c.Param("asset")
+	exchanges := GetExchangesParam(c)
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshots, err := LoadExchangesSnapshots(exchanges, q)
+	if err != nil {
+		glog.Errorf("getAsset: cannot load the snapshots of exchanges %v due to error %s", exchanges, err)
+		respondError(c, err)
+		return
+	}
+	r := markets.AssetMarkets(snapshots, asset)
+	if len(r.Markets) == 0 {
+		respondError(c, NewNotFoundError(fmt.Sprintf("asset '%s' is not traded on exchanges %v", asset, exchanges), nil))
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// maxConversionHops is the maximum number of trades of the conversion routes
+const maxConversionHops = 5
+
+// GetRouteOptionsParam returns the route search options from the 'max_hops', 'limit' and 'cross_exchange' query parameters
+func GetRouteOptionsParam(c *gin.Context) (markets.RouteOptions, error) {
+	opts := markets.DefaultRouteOptions()
+	if value := c.Request.URL.Query().Get("max_hops"); value != "" {
+		maxHops, err := strconv.Atoi(value)
+		if err != nil || maxHops <= 0 || maxHops > maxConversionHops {
+			return opts, NewInvalidParameterError("max_hops",
+				fmt.Sprintf("'max_hops' must be an integer between 1 and %d", maxConversionHops))
+		}
+		opts.MaxHops = maxHops
+	}
+	limit, err := GetLimitParam(c, opts.Limit, 100)
+	if err != nil {
+		return opts, NewInvalidParameterError("limit", "'limit' must be a positive integer")
+	}
+	opts.Limit = limit
+	if value := c.Request.URL.Query().Get("cross_exchange"); value != "" {
+		crossExchange, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, NewInvalidParameterError("cross_exchange", "'cross_exchange' must be a boolean")
+		}
+		opts.CrossExchange = crossExchange
+	}
+	return opts, nil
+}
+
+func getConversionRoutes(c *gin.Context) {
+	from := c.Request.URL.Query().Get("from")
+	if from == "" {
+		respondError(c, NewInvalidParameterError("from", "'from' asset is required"))
+		return
+	}
+	to := c.Request.URL.Query().Get("to")
+	if to == "" {
+		respondError(c, NewInvalidParameterError("to", "'to' asset is required"))
+		return
+	}
+	opts, err := GetRouteOptionsParam(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	exchanges := GetExchangesParam(c)
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshots, err := LoadExchangesSnapshots(exchanges, q)
+	if err != nil {
+		glog.Errorf("getConversionRoutes: cannot load the snapshots of exchanges %v due to error %s", exchanges, err)
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, markets.FindRoutes(snapshots, from, to, opts))
+}
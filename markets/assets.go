@@ -0,0 +1,95 @@
+package markets
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// symbolAssets returns the normalised assets of the symbol
+func symbolAssets(s *types.SymbolInfo) []string {
+	assets := []string{strings.ToUpper(s.BaseAsset)}
+	if s.QuoteAsset != "" && !strings.EqualFold(s.QuoteAsset, s.BaseAsset) {
+		assets = append(assets, strings.ToUpper(s.QuoteAsset))
+	}
+	return assets
+}
+
+// AssetMarkets returns the markets of the exchanges of the snapshots where the asset appears as the base or the quote asset.
+// The asset is matched by both the normalised and the native codes
+func AssetMarkets(snapshots map[string]*types.ExchangeSymbols, asset string) *types.APIAssetMarkets {
+	asset = strings.ToUpper(asset)
+	r := types.APIAssetMarkets{
+		Asset:     asset,
+		Exchanges: make([]string, 0),
+		Markets:   make([]types.APIAssetMarket, 0)}
+	for _, exchange := range sortedExchanges(snapshots) {
+		snapshot := snapshots[exchange]
+		listed := false
+		for i := range snapshot.Symbols {
+			s := &snapshot.Symbols[i]
+			role := ""
+			switch {
+			case strings.EqualFold(s.BaseAsset, asset) || strings.EqualFold(s.NativeBaseAsset, asset):
+				role = types.AssetRoleBase
+				r.Asset = strings.ToUpper(s.BaseAsset)
+			case s.QuoteAsset != "" && (strings.EqualFold(s.QuoteAsset, asset) || strings.EqualFold(s.NativeQuoteAsset, asset)):
+				role = types.AssetRoleQuote
+				r.Asset = strings.ToUpper(s.QuoteAsset)
+			default:
+				continue
+			}
+			r.Markets = append(r.Markets, types.APIAssetMarket{
+				Exchange:     exchange,
+				Symbol:       exchange + "-" + s.Symbol,
+				NativeSymbol: s.Symbol,
+				CanonicalID:  types.NewInstrumentID(exchange, s).String(),
+				Type:         s.Type,
+				Status:       s.Status,
+				Role:         role,
+				Asset:        s.BaseAsset,
+				Quote:        s.QuoteAsset})
+			listed = true
+		}
+		if listed {
+			r.Exchanges = append(r.Exchanges, exchange)
+		}
+	}
+	sort.SliceStable(r.Markets, func(i, j int) bool {
+		if r.Markets[i].Exchange != r.Markets[j].Exchange {
+			return r.Markets[i].Exchange < r.Markets[j].Exchange
+		}
+		return r.Markets[i].NativeSymbol < r.Markets[j].NativeSymbol
+	})
+	return &r
+}
+
+// Assets returns all the normalised assets of the exchanges of the snapshots with the number of markets
+// where they appear on every exchange
+func Assets(snapshots map[string]*types.ExchangeSymbols) *types.APIAssets {
+	summaries := make(map[string]*types.APIAssetSummary)
+	for exchange, snapshot := range snapshots {
+		for i := range snapshot.Symbols {
+			for _, asset := range symbolAssets(&snapshot.Symbols[i]) {
+				summary, ok := summaries[asset]
+				if !ok {
+					summary = &types.APIAssetSummary{
+						Asset:     asset,
+						Exchanges: make(map[string]int)}
+					summaries[asset] = summary
+				}
+				summary.Markets++
+				summary.Exchanges[exchange]++
+			}
+		}
+	}
+
+	r := types.APIAssets{
+		Assets: make([]types.APIAssetSummary, 0, len(summaries))}
+	for _, summary := range summaries {
+		r.Assets = append(r.Assets, *summary)
+	}
+	sort.Slice(r.Assets, func(i, j int) bool { return r.Assets[i].Asset < r.Assets[j].Asset })
+	return &r
+}
@@ -0,0 +1,58 @@
+package markets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestAssetMarkets(t *testing.T) {
+	r := AssetMarkets(testSnapshots(), "eth")
+	assert.Equal(t, "ETH", r.Asset)
+	assert.Equal(t, []string{"binance", "bitfinex"}, r.Exchanges)
+	assert.Equal(t, []types.APIAssetMarket{
+		{Exchange: "binance", Symbol: "binance-ETHBTC", NativeSymbol: "ETHBTC", CanonicalID: "binance:spot:ETH/BTC",
+			Type: "spot", Status: "TRADING", Role: types.AssetRoleBase, Asset: "ETH", Quote: "BTC"},
+		{Exchange: "bitfinex", Symbol: "bitfinex-tETHBTC", NativeSymbol: "tETHBTC", CanonicalID: "bitfinex:spot:ETH/BTC",
+			Type: "spot", Status: "TRADING", Role: types.AssetRoleBase, Asset: "ETH", Quote: "BTC"}}, r.Markets)
+}
+
+func TestAssetMarketsRoles(t *testing.T) {
+	r := AssetMarkets(testSnapshots(), "BTC")
+	roles := map[string]string{}
+	for _, m := range r.Markets {
+		roles[m.Symbol] = m.Role
+	}
+	assert.Equal(t, map[string]string{
+		"binance-BTCUSDT":  types.AssetRoleBase,
+		"binance-ETHBTC":   types.AssetRoleQuote,
+		"binance-XRPBTC":   types.AssetRoleQuote,
+		"bitfinex-fBTC":    types.AssetRoleBase,
+		"bitfinex-tBTCUSD": types.AssetRoleBase,
+		"bitfinex-tBTCUST": types.AssetRoleBase,
+		"bitfinex-tETHBTC": types.AssetRoleQuote}, roles)
+}
+
+func TestAssetMarketsNativeCode(t *testing.T) {
+	snapshots := map[string]*types.ExchangeSymbols{
+		"bitfinex": {Symbols: []types.SymbolInfo{
+			{Symbol: "tBTCUST", Type: "spot", BaseAsset: "BTC", QuoteAsset: "USDT", NativeBaseAsset: "BTC", NativeQuoteAsset: "UST"}}}}
+	r := AssetMarkets(snapshots, "UST")
+	assert.Equal(t, "USDT", r.Asset)
+	assert.Equal(t, 1, len(r.Markets))
+
+	r = AssetMarkets(snapshots, "DOGE")
+	assert.Equal(t, 0, len(r.Markets))
+}
+
+func TestAssets(t *testing.T) {
+	r := Assets(testSnapshots())
+	assert.Equal(t, []types.APIAssetSummary{
+		{Asset: "BTC", Markets: 7, Exchanges: map[string]int{"binance": 3, "bitfinex": 4}},
+		{Asset: "ETH", Markets: 2, Exchanges: map[string]int{"binance": 1, "bitfinex": 1}},
+		{Asset: "USD", Markets: 1, Exchanges: map[string]int{"bitfinex": 1}},
+		{Asset: "USDT", Markets: 2, Exchanges: map[string]int{"binance": 1, "bitfinex": 1}},
+		{Asset: "XRP", Markets: 1, Exchanges: map[string]int{"binance": 1}}}, r.Assets)
+}
@@ -0,0 +1,75 @@
+package markets
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// IsSpot returns true if the symbol is a spot market. The symbols stored before the instrument types were introduced are spot markets
+func IsSpot(s *types.SymbolInfo) bool {
+	return (s.Type == "" || s.Type == types.InstrumentTypeSpot) && s.QuoteAsset != ""
+}
+
+// sortedExchanges returns the names of the exchanges of the snapshots in alphabetical order
+func sortedExchanges(snapshots map[string]*types.ExchangeSymbols) []string {
+	exchanges := make([]string, 0, len(snapshots))
+	for e := range snapshots {
+		exchanges = append(exchanges, e)
+	}
+	sort.Strings(exchanges)
+	return exchanges
+}
+
+// CommonMarkets returns the spot markets listed on at least minVenues of the exchanges of the snapshots, keyed by the
+// exchange name. The markets are matched by the normalised base and quote assets. If quotes is not empty, only
+// the markets with these quote assets are returned
+func CommonMarkets(snapshots map[string]*types.ExchangeSymbols, quotes map[string]bool, minVenues int) *types.APICommonMarkets {
+	type pair struct {
+		base  string
+		quote string
+	}
+	exchanges := sortedExchanges(snapshots)
+	venues := make(map[pair][]types.APIMarketVenue)
+	listedOn := make(map[pair]map[string]bool)
+	for _, exchange := range exchanges {
+		snapshot := snapshots[exchange]
+		for i := range snapshot.Symbols {
+			s := &snapshot.Symbols[i]
+			if !IsSpot(s) {
+				continue
+			}
+			p := pair{base: strings.ToUpper(s.BaseAsset), quote: strings.ToUpper(s.QuoteAsset)}
+			if len(quotes) > 0 && !quotes[p.quote] {
+				continue
+			}
+			venues[p] = append(venues[p], types.ConvertMarketVenue(exchange, s))
+			if listedOn[p] == nil {
+				listedOn[p] = make(map[string]bool)
+			}
+			listedOn[p][exchange] = true
+		}
+	}
+
+	r := types.APICommonMarkets{
+		Exchanges: exchanges,
+		Markets:   make([]types.APICommonMarket, 0)}
+	for p, v := range venues {
+		if len(listedOn[p]) < minVenues {
+			continue
+		}
+		sort.SliceStable(v, func(i, j int) bool { return v[i].Exchange < v[j].Exchange })
+		r.Markets = append(r.Markets, types.APICommonMarket{
+			Base:   p.base,
+			Quote:  p.quote,
+			Venues: v})
+	}
+	sort.Slice(r.Markets, func(i, j int) bool {
+		if r.Markets[i].Base != r.Markets[j].Base {
+			return r.Markets[i].Base < r.Markets[j].Base
+		}
+		return r.Markets[i].Quote < r.Markets[j].Quote
+	})
+	return &r
+}
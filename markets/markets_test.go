@@ -0,0 +1,60 @@
+package markets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testSnapshots() map[string]*types.ExchangeSymbols {
+	return map[string]*types.ExchangeSymbols{
+		"binance": {Symbols: []types.SymbolInfo{
+			{Symbol: "BTCUSDT", Status: "TRADING", Type: "spot", BaseAsset: "BTC", QuoteAsset: "USDT", BaseAssetPrecision: 8, QuotePrecision: 8},
+			{Symbol: "ETHBTC", Status: "TRADING", Type: "spot", BaseAsset: "ETH", QuoteAsset: "BTC", BaseAssetPrecision: 8, QuotePrecision: 8},
+			{Symbol: "XRPBTC", Status: "BREAK", Type: "spot", BaseAsset: "XRP", QuoteAsset: "BTC", BaseAssetPrecision: 8, QuotePrecision: 8}}},
+		"bitfinex": {Symbols: []types.SymbolInfo{
+			{Symbol: "tBTCUST", Status: "TRADING", Type: "spot", BaseAsset: "BTC", QuoteAsset: "USDT", BaseAssetPrecision: 5, QuotePrecision: 5},
+			{Symbol: "tETHBTC", Status: "TRADING", Type: "spot", BaseAsset: "ETH", QuoteAsset: "BTC", BaseAssetPrecision: 5, QuotePrecision: 5},
+			{Symbol: "tBTCUSD", Status: "TRADING", Type: "spot", BaseAsset: "BTC", QuoteAsset: "USD", BaseAssetPrecision: 5, QuotePrecision: 5},
+			{Symbol: "fBTC", Status: "TRADING", Type: "funding", BaseAsset: "BTC"}}}}
+}
+
+func marketNames(r *types.APICommonMarkets) []string {
+	names := []string{}
+	for _, m := range r.Markets {
+		names = append(names, m.Base+"/"+m.Quote)
+	}
+	return names
+}
+
+func TestCommonMarkets(t *testing.T) {
+	r := CommonMarkets(testSnapshots(), map[string]bool{}, 2)
+	assert.Equal(t, []string{"binance", "bitfinex"}, r.Exchanges)
+	assert.Equal(t, []string{"BTC/USDT", "ETH/BTC"}, marketNames(r))
+
+	btc := r.Markets[0]
+	assert.Equal(t, 2, len(btc.Venues))
+	assert.Equal(t, types.APIMarketVenue{
+		Exchange:       "bitfinex",
+		Symbol:         "bitfinex-tBTCUST",
+		NativeSymbol:   "tBTCUST",
+		CanonicalID:    "bitfinex:spot:BTC/USDT",
+		Status:         "TRADING",
+		AssetPrecision: 5,
+		QuotePrecision: 5}, btc.Venues[1])
+}
+
+func TestCommonMarketsQuote(t *testing.T) {
+	r := CommonMarkets(testSnapshots(), map[string]bool{"BTC": true}, 2)
+	assert.Equal(t, []string{"ETH/BTC"}, marketNames(r))
+
+	r = CommonMarkets(testSnapshots(), map[string]bool{"USD": true}, 2)
+	assert.Equal(t, []string{}, marketNames(r))
+}
+
+func TestCommonMarketsMinVenues(t *testing.T) {
+	r := CommonMarkets(testSnapshots(), map[string]bool{}, 1)
+	assert.Equal(t, []string{"BTC/USD", "BTC/USDT", "ETH/BTC", "XRP/BTC"}, marketNames(r))
+}
@@ -0,0 +1,162 @@
+package markets
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// RouteOptions type contains the limits of the conversion routes search
+type RouteOptions struct {
+	// MaxHops is the maximum number of trades in a route
+	MaxHops int
+	// Limit is the maximum number of returned routes
+	Limit int
+	// CrossExchange allows the routes trading on several exchanges
+	CrossExchange bool
+}
+
+// DefaultRouteOptions returns the options of the search of the routes within one exchange up to 3 trades
+func DefaultRouteOptions() RouteOptions {
+	return RouteOptions{
+		MaxHops: 3,
+		Limit:   10}
+}
+
+// graph type maps every asset to the trades converting it into another asset
+type graph map[string][]types.APIConversionHop
+
+// newGraph creates the graph of the trading spot markets of the exchanges of the snapshots
+func newGraph(snapshots map[string]*types.ExchangeSymbols, exchanges []string) graph {
+	g := make(graph)
+	for _, exchange := range exchanges {
+		snapshot := snapshots[exchange]
+		for i := range snapshot.Symbols {
+			s := &snapshot.Symbols[i]
+			if !IsSpot(s) || s.Status != types.StatusTrading {
+				continue
+			}
+			base, quote := strings.ToUpper(s.BaseAsset), strings.ToUpper(s.QuoteAsset)
+			if base == quote {
+				continue
+			}
+			venue := types.ConvertMarketVenue(exchange, s)
+			hop := types.APIConversionHop{
+				Exchange:     exchange,
+				Symbol:       venue.Symbol,
+				NativeSymbol: venue.NativeSymbol,
+				CanonicalID:  venue.CanonicalID}
+			sell, buy := hop, hop
+			sell.From, sell.To, sell.Side = base, quote, types.SideSell
+			buy.From, buy.To, buy.Side = quote, base, types.SideBuy
+			g[base] = append(g[base], sell)
+			g[quote] = append(g[quote], buy)
+		}
+	}
+	for _, hops := range g {
+		sort.Slice(hops, func(i, j int) bool {
+			if hops[i].To != hops[j].To {
+				return hops[i].To < hops[j].To
+			}
+			if hops[i].Exchange != hops[j].Exchange {
+				return hops[i].Exchange < hops[j].Exchange
+			}
+			return hops[i].NativeSymbol < hops[j].NativeSymbol
+		})
+	}
+	return g
+}
+
+// distancesTo returns the number of trades needed to convert every asset into the target asset up to maxHops
+func (g graph) distancesTo(target string, maxHops int) map[string]int {
+	reverse := make(map[string][]string)
+	for from, hops := range g {
+		for _, h := range hops {
+			reverse[h.To] = append(reverse[h.To], from)
+		}
+	}
+	distances := map[string]int{target: 0}
+	queue := []string{target}
+	for len(queue) > 0 {
+		asset := queue[0]
+		queue = queue[1:]
+		if distances[asset] == maxHops {
+			continue
+		}
+		for _, from := range reverse[asset] {
+			if _, ok := distances[from]; !ok {
+				distances[from] = distances[asset] + 1
+				queue = append(queue, from)
+			}
+		}
+	}
+	return distances
+}
+
+// shortestRoutes returns up to limit shortest routes converting the asset from into the asset to
+func (g graph) shortestRoutes(from, to string, opts RouteOptions) []types.APIConversionRoute {
+	routes := make([]types.APIConversionRoute, 0)
+	distances := g.distancesTo(to, opts.MaxHops)
+	if _, ok := distances[from]; !ok || from == to {
+		return routes
+	}
+	var walk func(asset string, hops []types.APIConversionHop)
+	walk = func(asset string, hops []types.APIConversionHop) {
+		if len(routes) >= opts.Limit {
+			return
+		}
+		if asset == to {
+			route := types.APIConversionRoute{
+				Hops: make([]types.APIConversionHop, len(hops))}
+			copy(route.Hops, hops)
+			route.Exchanges = routeExchanges(route.Hops)
+			routes = append(routes, route)
+			return
+		}
+		for _, h := range g[asset] {
+			if d, ok := distances[h.To]; ok && d == distances[asset]-1 {
+				walk(h.To, append(hops, h))
+			}
+		}
+	}
+	walk(from, make([]types.APIConversionHop, 0, distances[from]))
+	return routes
+}
+
+// routeExchanges returns the exchanges of the hops in the order of the first trade on them
+func routeExchanges(hops []types.APIConversionHop) []string {
+	exchanges := make([]string, 0)
+	listed := make(map[string]bool)
+	for _, h := range hops {
+		if !listed[h.Exchange] {
+			exchanges = append(exchanges, h.Exchange)
+			listed[h.Exchange] = true
+		}
+	}
+	return exchanges
+}
+
+// FindRoutes returns the shortest routes converting the asset from into the asset to by trading the TRADING spot markets
+// of the exchanges of the snapshots. Unless opts.CrossExchange is set, every route trades on one exchange only
+func FindRoutes(snapshots map[string]*types.ExchangeSymbols, from, to string, opts RouteOptions) *types.APIConversionRoutes {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	r := types.APIConversionRoutes{
+		From:   from,
+		To:     to,
+		Routes: make([]types.APIConversionRoute, 0)}
+
+	exchanges := sortedExchanges(snapshots)
+	if opts.CrossExchange {
+		r.Routes = newGraph(snapshots, exchanges).shortestRoutes(from, to, opts)
+		return &r
+	}
+	for _, exchange := range exchanges {
+		r.Routes = append(r.Routes, newGraph(snapshots, []string{exchange}).shortestRoutes(from, to, opts)...)
+	}
+	sort.SliceStable(r.Routes, func(i, j int) bool { return len(r.Routes[i].Hops) < len(r.Routes[j].Hops) })
+	if len(r.Routes) > opts.Limit {
+		r.Routes = r.Routes[:opts.Limit]
+	}
+	return &r
+}
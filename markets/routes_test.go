@@ -0,0 +1,74 @@
+package markets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func routeNames(r *types.APIConversionRoutes) []string {
+	names := []string{}
+	for _, route := range r.Routes {
+		hops := []string{}
+		for _, h := range route.Hops {
+			hops = append(hops, h.Side+" "+h.Symbol)
+		}
+		names = append(names, strings.Join(hops, ", "))
+	}
+	return names
+}
+
+func TestFindRoutesDirect(t *testing.T) {
+	r := FindRoutes(testSnapshots(), "usdt", "btc", DefaultRouteOptions())
+	assert.Equal(t, "USDT", r.From)
+	assert.Equal(t, "BTC", r.To)
+	assert.Equal(t, []string{"buy binance-BTCUSDT", "buy bitfinex-tBTCUST"}, routeNames(r))
+	assert.Equal(t, types.APIConversionHop{
+		Exchange:     "binance",
+		Symbol:       "binance-BTCUSDT",
+		NativeSymbol: "BTCUSDT",
+		CanonicalID:  "binance:spot:BTC/USDT",
+		From:         "USDT",
+		To:           "BTC",
+		Side:         types.SideBuy}, r.Routes[0].Hops[0])
+}
+
+func TestFindRoutesSeveralHops(t *testing.T) {
+	r := FindRoutes(testSnapshots(), "ETH", "USD", DefaultRouteOptions())
+	assert.Equal(t, []string{"sell bitfinex-tETHBTC, sell bitfinex-tBTCUSD"}, routeNames(r))
+	assert.Equal(t, []string{"bitfinex"}, r.Routes[0].Exchanges)
+}
+
+func TestFindRoutesSkipsNotTrading(t *testing.T) {
+	r := FindRoutes(testSnapshots(), "XRP", "BTC", DefaultRouteOptions())
+	assert.Equal(t, []string{}, routeNames(r))
+}
+
+func TestFindRoutesCrossExchange(t *testing.T) {
+	snapshots := map[string]*types.ExchangeSymbols{
+		"binance": {Symbols: []types.SymbolInfo{
+			{Symbol: "LTCBTC", Status: "TRADING", Type: "spot", BaseAsset: "LTC", QuoteAsset: "BTC"}}},
+		"bitfinex": {Symbols: []types.SymbolInfo{
+			{Symbol: "tBTCUSD", Status: "TRADING", Type: "spot", BaseAsset: "BTC", QuoteAsset: "USD"}}}}
+
+	opts := DefaultRouteOptions()
+	assert.Equal(t, []string{}, routeNames(FindRoutes(snapshots, "LTC", "USD", opts)))
+
+	opts.CrossExchange = true
+	r := FindRoutes(snapshots, "LTC", "USD", opts)
+	assert.Equal(t, []string{"sell binance-LTCBTC, sell bitfinex-tBTCUSD"}, routeNames(r))
+	assert.Equal(t, []string{"binance", "bitfinex"}, r.Routes[0].Exchanges)
+}
+
+func TestFindRoutesLimits(t *testing.T) {
+	opts := DefaultRouteOptions()
+	opts.MaxHops = 1
+	assert.Equal(t, []string{}, routeNames(FindRoutes(testSnapshots(), "ETH", "USD", opts)))
+
+	opts = DefaultRouteOptions()
+	opts.Limit = 1
+	assert.Equal(t, []string{"buy binance-BTCUSDT"}, routeNames(FindRoutes(testSnapshots(), "USDT", "BTC", opts)))
+}
@@ -0,0 +1,156 @@
+// Package metrics contains the Prometheus metrics of the registry
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+const namespace = "registry"
+
+// The outcomes of the fetches
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+var (
+	// FetchDuration is the duration of the fetches of the symbols by exchange and outcome
+	FetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "fetch_duration_seconds",
+		Help:      "Duration of the fetches of the symbols from the exchanges.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}}, []string{"exchange", "outcome"})
+
+	// DBDuration is the duration of the DB operations
+	DBDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_operation_duration_seconds",
+		Help:      "Duration of the reads and writes of the snapshots in the DB.",
+		Buckets:   prometheus.DefBuckets}, []string{"operation"})
+
+	// DBErrors is the number of the failed DB operations
+	DBErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_errors_total",
+		Help:      "Number of the failed reads and writes of the snapshots in the DB."}, []string{"operation"})
+
+	// HTTPRequests is the number of the HTTP requests by handler, method and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of the HTTP requests."}, []string{"handler", "method", "status"})
+
+	// HTTPDuration is the duration of the HTTP requests by handler and method
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the HTTP requests.",
+		Buckets:   prometheus.DefBuckets}, []string{"handler", "method"})
+
+	// StaleSnapshots is 1 for the exchanges which latest snapshot is older than their maximum age and 0 otherwise
+	StaleSnapshots = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "snapshot_stale",
+		Help:      "Whether the latest snapshot of the exchange is older than its maximum age."}, []string{"exchange"})
+
+	// StaleAlerts is the number of the alerts on the exchanges becoming stale
+	StaleAlerts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stale_alerts_total",
+		Help:      "Number of the alerts on the latest snapshot of the exchange becoming stale."}, []string{"exchange"})
+
+	// QuarantinedSnapshots is the number of the snapshots rejected by the validation by exchange and violated rule
+	QuarantinedSnapshots = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quarantined_snapshots_total",
+		Help:      "Number of the snapshots rejected by the validation."}, []string{"exchange", "rule"})
+
+	// ChangeEvents is the number of the change events by exchange and type
+	ChangeEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "change_events_total",
+		Help:      "Number of the change events of the symbols."}, []string{"exchange", "type"})
+)
+
+func init() {
+	prometheus.MustRegister(FetchDuration, DBDuration, DBErrors, HTTPRequests, HTTPDuration, StaleSnapshots, StaleAlerts,
+		QuarantinedSnapshots, ChangeEvents)
+}
+
+// ObserveFetch records the duration and the outcome of the fetch of the exchange started at start
+func ObserveFetch(exchange string, start time.Time, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	FetchDuration.WithLabelValues(exchange, outcome).Observe(time.Since(start).Seconds())
+}
+
+// ObserveDB records the duration of the DB operation started at start and counts it as failed if err is not nil
+func ObserveDB(operation string, start time.Time, err error) {
+	DBDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		DBErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// Middleware returns the gin middleware counting the requests and observing their duration.
+// The requests are labelled by the name of their handler as the routes are not known to the middleware
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		handler := c.HandlerName()
+		// The middleware itself is the last handler of the requests not matching any route
+		if strings.HasPrefix(handler, "github.com/etrubenok/make-trades-registry/metrics.Middleware") {
+			handler = "unmatched"
+		}
+		handler = handler[strings.LastIndex(handler, "/")+1:]
+		HTTPRequests.WithLabelValues(handler, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		HTTPDuration.WithLabelValues(handler, c.Request.Method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// snapshotsCollector reports the number of symbols and the age of the latest snapshot of every exchange
+type snapshotsCollector struct {
+	symbols   *prometheus.Desc
+	age       *prometheus.Desc
+	snapshots func() map[string]*types.ExchangeSymbols
+	now       func() time.Time
+}
+
+// NewSnapshotsCollector creates the collector of the number of symbols and the age of the snapshots returned by snapshots
+// keyed by the exchange name. The age is computed on every scrape
+func NewSnapshotsCollector(snapshots func() map[string]*types.ExchangeSymbols) prometheus.Collector {
+	c := snapshotsCollector{
+		symbols: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "symbols"),
+			"Number of the symbols in the latest snapshot of the exchange.", []string{"exchange"}, nil),
+		age: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "snapshot_age_seconds"),
+			"Age of the latest snapshot of the exchange.", []string{"exchange"}, nil),
+		snapshots: snapshots,
+		now:       time.Now}
+	return &c
+}
+
+// Describe sends the descriptors of the metrics
+func (c *snapshotsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.symbols
+	ch <- c.age
+}
+
+// Collect sends the current values of the metrics
+func (c *snapshotsCollector) Collect(ch chan<- prometheus.Metric) {
+	now := c.now()
+	for exchange, s := range c.snapshots() {
+		taken := time.Unix(0, s.SnapshotTime*int64(time.Millisecond))
+		ch <- prometheus.MustNewConstMetric(c.symbols, prometheus.GaugeValue, float64(len(s.Symbols)), exchange)
+		ch <- prometheus.MustNewConstMetric(c.age, prometheus.GaugeValue, now.Sub(taken).Seconds(), exchange)
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestObserveDB(t *testing.T) {
+	errorsBefore := testutil.ToFloat64(DBErrors.WithLabelValues("test_read"))
+	ObserveDB("test_read", time.Now(), nil)
+	ObserveDB("test_read", time.Now(), errors.New("failure"))
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(DBErrors.WithLabelValues("test_read")))
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/test", testHandler)
+
+	handled := HTTPRequests.WithLabelValues("metrics.testHandler", "GET", "200")
+	unmatched := HTTPRequests.WithLabelValues("unmatched", "GET", "404")
+	handledBefore, unmatchedBefore := testutil.ToFloat64(handled), testutil.ToFloat64(unmatched)
+	for _, path := range []string{"/test", "/test", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Equal(t, handledBefore+2, testutil.ToFloat64(handled))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
+
+func testHandler(c *gin.Context) {
+	c.Status(http.StatusOK)
+}
+
+func TestSnapshotsCollector(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 1, 0, 0, time.UTC)
+	c := NewSnapshotsCollector(func() map[string]*types.ExchangeSymbols {
+		return map[string]*types.ExchangeSymbols{"binance": {
+			SnapshotTime: now.Add(-time.Minute).UnixNano() / int64(time.Millisecond),
+			Symbols:      []types.SymbolInfo{{Symbol: "ETHBTC"}, {Symbol: "XRPBTC"}}}}
+	})
+	c.(*snapshotsCollector).now = func() time.Time { return now }
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	values := make(map[string]float64)
+	for _, f := range families {
+		values[f.GetName()] = f.GetMetric()[0].GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"registry_symbols": 2, "registry_snapshot_age_seconds": 60}, values)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/etrubenok/make-trades-registry/changes"
+	"github.com/etrubenok/make-trades-registry/db"
+	"github.com/etrubenok/make-trades-registry/metrics"
+	"github.com/etrubenok/make-trades-registry/publishers"
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-types/registry"
+	"github.com/golang/glog"
+)
+
+// Processor consumes the snapshots produced by the fetch job, saves them into the DB, updates the read cache and
+// publishes the changed ones together with the change events
+type Processor struct {
+	importer  db.DBImporter
+	publisher publishers.Publisher
+	cache     *db.CachingDBLoader
+	previous  map[int]*types.ExchangeSymbols
+	// published are the content hashes of the last published snapshots of the exchanges
+	published map[int]string
+}
+
+// NewProcessor instantiates Processor object. cache may be nil if there is no read cache to update
+func NewProcessor(importer db.DBImporter, publisher publishers.Publisher, cache *db.CachingDBLoader) *Processor {
+	p := Processor{
+		importer:  importer,
+		publisher: publisher,
+		cache:     cache,
+		previous:  make(map[int]*types.ExchangeSymbols),
+		published: make(map[int]string)}
+	return &p
+}
+
+// Seed makes the snapshots the previous ones of their exchanges, so the first processed snapshots are compared
+// with them and the changes since they were taken are published
+func (p *Processor) Seed(snapshots *types.ExchangesSymbols) {
+	for i := range snapshots.Exchanges {
+		previous := snapshots.Exchanges[i]
+		p.previous[previous.ExchangeID] = &previous
+	}
+}
+
+// Run processes the snapshots from the results channel until it is closed
+func (p *Processor) Run(results <-chan types.ExchangesSymbols) {
+	for r := range results {
+		if err := p.Process(&r); err != nil {
+			glog.Errorf("Processor.Run: cannot process the snapshots due to error %s", err)
+		}
+	}
+}
+
+// Process saves the snapshots, makes them the latest ones of the read cache and publishes the snapshot
+// of every exchange whose content changed since the last published one followed by the change events
+// against the previous snapshot
+func (p *Processor) Process(snapshots *types.ExchangesSymbols) error {
+	if err := p.importer.SaveSymbolsSnapshots(snapshots); err != nil {
+		glog.Errorf("Processor.Process: cannot save the snapshots due to error %s", err)
+		return err
+	}
+	if p.cache != nil {
+		p.cache.Update(snapshots)
+	}
+	for i := range snapshots.Exchanges {
+		e := &snapshots.Exchanges[i]
+		exchange, err := registry.GetExchangeNameByID(e.ExchangeID)
+		if err != nil {
+			glog.Errorf("Processor.Process: cannot get exchange name by id '%d' due to error %s", e.ExchangeID, err)
+			return err
+		}
+
+		messages := make([]publishers.Message, 0)
+		contentHash := e.ContentHash()
+		if p.published[e.ExchangeID] != contentHash {
+			messages = append(messages, publishers.NewSnapshotMessage(exchange, e))
+		}
+		if previous, ok := p.previous[e.ExchangeID]; ok {
+			events := changes.Events(changes.Diff(previous, e))
+			for j := range events {
+				messages = append(messages, publishers.NewChangeMessage(exchange, &events[j]))
+				metrics.ChangeEvents.WithLabelValues(exchange, events[j].Type).Inc()
+			}
+			glog.V(1).Infof("Processor.Process: %d change events for exchange '%s'", len(events), exchange)
+		}
+		if len(messages) > 0 {
+			if err := p.publisher.Publish(messages); err != nil {
+				glog.Errorf("Processor.Process: cannot publish %d messages for exchange '%s' due to error %s", len(messages), exchange, err)
+				return err
+			}
+		}
+		p.published[e.ExchangeID] = contentHash
+		previous := *e
+		p.previous[e.ExchangeID] = &previous
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/etrubenok/make-trades-registry/publishers"
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-types/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+type testImporter struct {
+	saved []types.ExchangesSymbols
+}
+
+func (i *testImporter) SaveSymbolsSnapshots(snapshots *types.ExchangesSymbols) error {
+	i.saved = append(i.saved, *snapshots)
+	return nil
+}
+
+func TestProcessorProcess(t *testing.T) {
+	binanceID, err := registry.GetExchangeID("binance")
+	assert.NoError(t, err)
+	publisher := publishers.NewMemoryPublisher()
+	importer := &testImporter{}
+	p := NewProcessor(importer, publisher, nil)
+
+	err = p.Process(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{{
+		ExchangeID:   binanceID,
+		SnapshotTime: 1000,
+		Symbols:      []types.SymbolInfo{{Symbol: "BTCUSDT"}}}}})
+	assert.NoError(t, err)
+	assert.Len(t, importer.saved, 1)
+	messages := publisher.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, publishers.MessageTypeSnapshot, messages[0].Type)
+	assert.Equal(t, "binance", messages[0].Key())
+
+	err = p.Process(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{{
+		ExchangeID:   binanceID,
+		SnapshotTime: 2000,
+		Symbols:      []types.SymbolInfo{{Symbol: "BTCUSDT"}, {Symbol: "ETHBTC"}}}}})
+	assert.NoError(t, err)
+	messages = publisher.Messages()
+	assert.Len(t, messages, 3)
+	assert.Equal(t, publishers.MessageTypeChange, messages[2].Type)
+	assert.Equal(t, types.ChangeAdded, messages[2].Change.Type)
+	assert.Equal(t, "ETHBTC", messages[2].Change.Symbol)
+	assert.Equal(t, int64(1000), messages[2].Change.PreviousSnapshotTime)
+
+	// Nothing is published for an unchanged snapshot
+	err = p.Process(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{{
+		ExchangeID:   binanceID,
+		SnapshotTime: 3000,
+		Symbols:      []types.SymbolInfo{{Symbol: "ETHBTC"}, {Symbol: "BTCUSDT"}}}}})
+	assert.NoError(t, err)
+	assert.Len(t, importer.saved, 3)
+	assert.Len(t, publisher.Messages(), 3)
+}
+
+func TestProcessorSeed(t *testing.T) {
+	binanceID, err := registry.GetExchangeID("binance")
+	assert.NoError(t, err)
+	publisher := publishers.NewMemoryPublisher()
+	p := NewProcessor(&testImporter{}, publisher, nil)
+	p.Seed(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{{
+		ExchangeID:   binanceID,
+		SnapshotTime: 1000,
+		Symbols:      []types.SymbolInfo{{Symbol: "BTCUSDT"}, {Symbol: "ETHBTC"}}}}})
+
+	err = p.Process(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{{
+		ExchangeID:   binanceID,
+		SnapshotTime: 2000,
+		Symbols:      []types.SymbolInfo{{Symbol: "BTCUSDT"}}}}})
+	assert.NoError(t, err)
+	messages := publisher.Messages()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, types.ChangeRemoved, messages[1].Change.Type)
+	assert.Equal(t, "ETHBTC", messages[1].Change.Symbol)
+}
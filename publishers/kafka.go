@@ -0,0 +1,54 @@
+package publishers
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher publishes the messages into a Kafka topic
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher instantiates KafkaPublisher object.
+// The messages are partitioned by their keys so all the messages of one exchange land into the same partition
+func NewKafkaPublisher(brokers []string, topic string) Publisher {
+	p := KafkaPublisher{
+		writer: kafka.NewWriter(kafka.WriterConfig{
+			Brokers:      brokers,
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			WriteTimeout: 10 * time.Second})}
+	return &p
+}
+
+// Publish writes the messages into the Kafka topic
+func (p *KafkaPublisher) Publish(messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, m := range messages {
+		value, err := m.Encode()
+		if err != nil {
+			glog.Errorf("KafkaPublisher.Publish: cannot encode message of type '%s' for exchange '%s' due to error %s", m.Type, m.Exchange, err)
+			return err
+		}
+		kafkaMessages[i] = kafka.Message{
+			Key:   []byte(m.Key()),
+			Value: value}
+	}
+	if err := p.writer.WriteMessages(context.Background(), kafkaMessages...); err != nil {
+		glog.Errorf("KafkaPublisher.Publish: cannot write %d messages into Kafka due to error %s", len(kafkaMessages), err)
+		return err
+	}
+	return nil
+}
+
+// Close flushes and closes the Kafka writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,86 @@
+package publishers
+
+import (
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// memoryPublisherLimit is the number of the recent messages kept by MemoryPublisher
+const memoryPublisherLimit = 100
+
+// MemoryPublisher keeps the recent published messages in memory and delivers them to in-process subscribers
+type MemoryPublisher struct {
+	mutex       sync.Mutex
+	messages    []Message
+	subscribers []chan<- Message
+}
+
+// NewMemoryPublisher instantiates MemoryPublisher object
+func NewMemoryPublisher() *MemoryPublisher {
+	p := MemoryPublisher{
+		messages:    make([]Message, 0),
+		subscribers: make([]chan<- Message, 0)}
+	return &p
+}
+
+// Subscribe registers the channel to receive all the messages published after the subscription
+func (p *MemoryPublisher) Subscribe(ch chan<- Message) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.subscribers = append(p.subscribers, ch)
+}
+
+// Messages returns the recent messages published so far, up to memoryPublisherLimit
+func (p *MemoryPublisher) Messages() []Message {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	r := make([]Message, len(p.messages))
+	copy(r, p.messages)
+	return r
+}
+
+// Publish stores the messages and sends them to the subscribers. A subscriber which channel is full is dropped
+// and its channel is closed, so a subscriber not reading never blocks the publishing
+func (p *MemoryPublisher) Publish(messages []Message) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.messages = append(p.messages, messages...)
+	if len(p.messages) > memoryPublisherLimit {
+		p.messages = append(make([]Message, 0, memoryPublisherLimit), p.messages[len(p.messages)-memoryPublisherLimit:]...)
+	}
+	subscribers := p.subscribers[:0]
+	for _, s := range p.subscribers {
+		if deliver(s, messages) {
+			subscribers = append(subscribers, s)
+			continue
+		}
+		glog.Warningf("MemoryPublisher.Publish: dropping slow subscriber")
+		close(s)
+	}
+	p.subscribers = subscribers
+	return nil
+}
+
+// deliver sends the messages to the channel without blocking, it returns false if the channel is full
+func deliver(ch chan<- Message, messages []Message) bool {
+	for _, m := range messages {
+		select {
+		case ch <- m:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Close closes the channels of all the subscribers
+func (p *MemoryPublisher) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, s := range p.subscribers {
+		close(s)
+	}
+	p.subscribers = nil
+	return nil
+}
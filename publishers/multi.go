@@ -0,0 +1,41 @@
+package publishers
+
+import (
+	"github.com/golang/glog"
+)
+
+// MultiPublisher publishes the messages to several publishers
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher instantiates MultiPublisher object
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	p := MultiPublisher{
+		publishers: publishers}
+	return &p
+}
+
+// Publish publishes the messages to every publisher. All the publishers are tried, the last error is returned
+func (p *MultiPublisher) Publish(messages []Message) error {
+	var lastErr error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(messages); err != nil {
+			glog.Errorf("MultiPublisher.Publish: cannot publish %d messages due to error %s", len(messages), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Close closes all the publishers
+func (p *MultiPublisher) Close() error {
+	var lastErr error
+	for _, publisher := range p.publishers {
+		if err := publisher.Close(); err != nil {
+			glog.Errorf("MultiPublisher.Close: cannot close publisher due to error %s", err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,54 @@
+package publishers
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	nats "github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes the messages into NATS subjects '<subject>.<type>.<exchange>'
+type NATSPublisher struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATSPublisher instantiates NATSPublisher object connected to the NATS server
+func NewNATSPublisher(url string, subject string) (Publisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		glog.Errorf("NewNATSPublisher: cannot connect to NATS server '%s' due to error %s", url, err)
+		return nil, err
+	}
+	p := NATSPublisher{
+		conn:    conn,
+		subject: subject}
+	return &p, nil
+}
+
+// Subject returns the NATS subject of the message
+func (p *NATSPublisher) Subject(m *Message) string {
+	return fmt.Sprintf("%s.%s.%s", p.subject, m.Type, m.Key())
+}
+
+// Publish sends the messages into NATS
+func (p *NATSPublisher) Publish(messages []Message) error {
+	for _, m := range messages {
+		data, err := m.Encode()
+		if err != nil {
+			glog.Errorf("NATSPublisher.Publish: cannot encode message of type '%s' for exchange '%s' due to error %s", m.Type, m.Exchange, err)
+			return err
+		}
+		if err := p.conn.Publish(p.Subject(&m), data); err != nil {
+			glog.Errorf("NATSPublisher.Publish: cannot publish message into NATS due to error %s", err)
+			return err
+		}
+	}
+	return p.conn.Flush()
+}
+
+// Close closes the connection to NATS
+func (p *NATSPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,137 @@
+package publishers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// SchemaVersion is the version of the payload schema of the published messages.
+// It must be incremented on every incompatible change of Message
+const SchemaVersion = 1
+
+const (
+	// MessageTypeSnapshot is the type of the message carrying a full snapshot of symbols of an exchange
+	MessageTypeSnapshot = "snapshot"
+	// MessageTypeChange is the type of the message carrying one change event of a symbol
+	MessageTypeChange = "change"
+)
+
+// Message type is the payload published to the message bus
+type Message struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Type          string                 `json:"type"`
+	Exchange      string                 `json:"exchange"`
+	SnapshotTime  int64                  `json:"snapshot_time"`
+	Snapshot      *types.ExchangeSymbols `json:"snapshot,omitempty"`
+	Change        *types.ChangeEvent     `json:"change,omitempty"`
+}
+
+// Key returns the key of the message. The messages are keyed by exchange so that the order of
+// the messages of one exchange is preserved by the message bus
+func (m *Message) Key() string {
+	return m.Exchange
+}
+
+// Encode serialises the message into the wire format
+func (m *Message) Encode() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// DecodeMessage deserialises a message from the wire format
+func DecodeMessage(data []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		glog.Errorf("DecodeMessage: cannot unmarshal message due to error %s", err)
+		return nil, err
+	}
+	if m.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("DecodeMessage: unsupported schema version %d", m.SchemaVersion)
+	}
+	return &m, nil
+}
+
+// NewSnapshotMessage creates a message for the snapshot of symbols of an exchange
+func NewSnapshotMessage(exchange string, snapshot *types.ExchangeSymbols) Message {
+	return Message{
+		SchemaVersion: SchemaVersion,
+		Type:          MessageTypeSnapshot,
+		Exchange:      exchange,
+		SnapshotTime:  snapshot.SnapshotTime,
+		Snapshot:      snapshot}
+}
+
+// NewChangeMessage creates a message for a change event of a symbol of an exchange
+func NewChangeMessage(exchange string, event *types.ChangeEvent) Message {
+	return Message{
+		SchemaVersion: SchemaVersion,
+		Type:          MessageTypeChange,
+		Exchange:      exchange,
+		SnapshotTime:  event.SnapshotTime,
+		Change:        event}
+}
+
+// Publisher is the interface for all the publishers of snapshots and change events
+type Publisher interface {
+	Publish(messages []Message) error
+	Close() error
+}
+
+// Config type contains the settings of all the publishers
+type Config struct {
+	KafkaBrokers []string
+	KafkaTopic   string
+	NATSURL      string
+	NATSSubject  string
+}
+
+// PublisherFactory creates a required publisher based on its name
+func PublisherFactory(name string, config *Config) (Publisher, error) {
+	switch name {
+	case "", "none":
+		return NewNopPublisher(), nil
+	case "memory":
+		return NewMemoryPublisher(), nil
+	case "kafka":
+		return NewKafkaPublisher(config.KafkaBrokers, config.KafkaTopic), nil
+	case "nats":
+		return NewNATSPublisher(config.NATSURL, config.NATSSubject)
+	default:
+		return nil, fmt.Errorf("PublisherFactory: publisher '%s' is not supported", name)
+	}
+}
+
+// ParseBrokers splits the comma separated list of brokers
+func ParseBrokers(brokers string) []string {
+	r := make([]string, 0)
+	for _, b := range strings.Split(brokers, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			r = append(r, b)
+		}
+	}
+	return r
+}
+
+// NopPublisher is a publisher which discards all the messages
+type NopPublisher struct {
+}
+
+// NewNopPublisher instantiates NopPublisher object
+func NewNopPublisher() Publisher {
+	p := NopPublisher{}
+	return &p
+}
+
+// Publish discards the messages
+func (p *NopPublisher) Publish(messages []Message) error {
+	return nil
+}
+
+// Close does nothing
+func (p *NopPublisher) Close() error {
+	return nil
+}
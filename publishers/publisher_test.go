@@ -0,0 +1,77 @@
+package publishers
+
+import (
+	"testing"
+
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageEncodeDecode(t *testing.T) {
+	m := NewChangeMessage("binance", &types.ChangeEvent{
+		Type:         types.ChangeAdded,
+		ExchangeID:   1,
+		Symbol:       "BTCUSDT",
+		SnapshotTime: 2000})
+	data, err := m.Encode()
+	assert.NoError(t, err)
+
+	decoded, err := DecodeMessage(data)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, decoded.SchemaVersion)
+	assert.Equal(t, "binance", decoded.Key())
+	assert.Equal(t, "BTCUSDT", decoded.Change.Symbol)
+	assert.Nil(t, decoded.Snapshot)
+}
+
+func TestDecodeMessageUnsupportedVersion(t *testing.T) {
+	_, err := DecodeMessage([]byte(`{"schema_version": 99, "type": "snapshot"}`))
+	assert.Error(t, err)
+}
+
+func TestMemoryPublisherSubscribe(t *testing.T) {
+	p := NewMemoryPublisher()
+	ch := make(chan Message, 2)
+	p.Subscribe(ch)
+
+	err := p.Publish([]Message{NewSnapshotMessage("bitfinex", &types.ExchangeSymbols{ExchangeID: 2, SnapshotTime: 1000})})
+	assert.NoError(t, err)
+	m := <-ch
+	assert.Equal(t, MessageTypeSnapshot, m.Type)
+	assert.Equal(t, int64(1000), m.SnapshotTime)
+
+	assert.NoError(t, p.Close())
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestParseBrokers(t *testing.T) {
+	assert.Equal(t, []string{"a:9092", "b:9092"}, ParseBrokers("a:9092, b:9092,"))
+}
+
+func TestMemoryPublisherSlowSubscriber(t *testing.T) {
+	p := NewMemoryPublisher()
+	slow := make(chan Message, 1)
+	fast := make(chan Message, 10)
+	p.Subscribe(slow)
+	p.Subscribe(fast)
+
+	for i := 0; i < 3; i++ {
+		err := p.Publish([]Message{NewSnapshotMessage("bitfinex", &types.ExchangeSymbols{ExchangeID: 2, SnapshotTime: int64(i)})})
+		assert.NoError(t, err)
+	}
+	assert.Len(t, fast, 3)
+	<-slow
+	_, ok := <-slow
+	assert.False(t, ok)
+}
+
+func TestMemoryPublisherLimit(t *testing.T) {
+	p := NewMemoryPublisher()
+	for i := 0; i < memoryPublisherLimit+10; i++ {
+		assert.NoError(t, p.Publish([]Message{NewSnapshotMessage("bitfinex", &types.ExchangeSymbols{ExchangeID: 2, SnapshotTime: int64(i)})}))
+	}
+	messages := p.Messages()
+	assert.Len(t, messages, memoryPublisherLimit)
+	assert.Equal(t, int64(10), messages[0].SnapshotTime)
+}
@@ -0,0 +1,118 @@
+package registrypb
+
+import (
+	"encoding/json"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// FromSymbolInfo converts the symbol of the exchange in DB format
+func FromSymbolInfo(exchange string, s *types.SymbolInfo) *SymbolInfo {
+	if s == nil {
+		return nil
+	}
+	r := SymbolInfo{
+		Symbol:             s.Symbol,
+		Status:             s.Status,
+		Type:               s.Type,
+		BaseAsset:          s.BaseAsset,
+		BaseAssetPrecision: s.BaseAssetPrecision,
+		QuoteAsset:         s.QuoteAsset,
+		QuotePrecision:     s.QuotePrecision,
+		OrderTypes:         s.OrderTypes,
+		IcebergAllowed:     s.IcebergAllowed,
+		Filters:            make([]*SymbolFilter, len(s.Filters)),
+		NativeBaseAsset:    s.NativeBaseAsset,
+		NativeQuoteAsset:   s.NativeQuoteAsset,
+		CanonicalId:        types.NewInstrumentID(exchange, s).String()}
+	for i, f := range s.Filters {
+		r.Filters[i] = &SymbolFilter{
+			FilterType: f.FilterType,
+			Params:     f.Params}
+	}
+	return &r
+}
+
+// FromExchangeSymbols converts the snapshot of the exchange in DB format
+func FromExchangeSymbols(exchange string, e *types.ExchangeSymbols) *ExchangeSymbols {
+	r := ExchangeSymbols{
+		Exchange:     exchange,
+		ExchangeId:   int32(e.ExchangeID),
+		SnapshotTime: e.SnapshotTime,
+		SnapshotDate: e.SnapshotDate(),
+		ContentHash:  e.ContentHash(),
+		Symbols:      make([]*SymbolInfo, len(e.Symbols))}
+	for i := range e.Symbols {
+		r.Symbols[i] = FromSymbolInfo(exchange, &e.Symbols[i])
+	}
+	return &r
+}
+
+// FromChangeEvent converts the change event of the exchange. The values of the changed fields are encoded in JSON
+func FromChangeEvent(exchange string, e *types.ChangeEvent) *ChangeEvent {
+	r := ChangeEvent{
+		Type:                 e.Type,
+		Exchange:             exchange,
+		Symbol:               e.Symbol,
+		SnapshotTime:         e.SnapshotTime,
+		PreviousSnapshotTime: e.PreviousSnapshotTime,
+		Before:               FromSymbolInfo(exchange, e.Before),
+		After:                FromSymbolInfo(exchange, e.After),
+		Fields:               make([]*FieldChange, len(e.Fields))}
+	for i, f := range e.Fields {
+		r.Fields[i] = &FieldChange{
+			Field:  f.Field,
+			Before: encodeValue(f.Before),
+			After:  encodeValue(f.After)}
+	}
+	return &r
+}
+
+func encodeValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		glog.Errorf("encodeValue: cannot encode value '%v' due to error %s", v, err)
+		return ""
+	}
+	return string(data)
+}
+
+// FromAPIExchangesSymbols converts the symbols of the exchanges in API format
+func FromAPIExchangesSymbols(r *types.APIExchangesSymbols) *ApiExchangesSymbols {
+	pb := ApiExchangesSymbols{
+		Exchanges:  make([]*ApiExchangeSymbols, len(r.Exchanges)),
+		NextCursor: r.NextCursor}
+	for i := range r.Exchanges {
+		pb.Exchanges[i] = FromAPIExchangeSymbols(&r.Exchanges[i])
+	}
+	return &pb
+}
+
+// FromAPIExchangeSymbols converts the snapshot of one exchange in API format
+func FromAPIExchangeSymbols(e *types.APIExchangeSymbols) *ApiExchangeSymbols {
+	exchange := ApiExchangeSymbols{
+		Exchange:     e.Exchange,
+		SnapshotDate: e.SnapshotDate,
+		SnapshotTime: e.SnapshotTime,
+		ContentHash:  e.ContentHash,
+		Symbols:      make([]*ApiSymbolInfo, len(e.Symbols))}
+	for j, s := range e.Symbols {
+		exchange.Symbols[j] = &ApiSymbolInfo{
+			Symbol:       s.Symbol,
+			NativeSymbol: s.NativeSymbol,
+			CanonicalId:  s.CanonicalID,
+			Status:       s.Status,
+			Type:         s.Type,
+			Asset:        s.Asset,
+			Quote:        s.Quote}
+	}
+	if e.Freshness != nil {
+		exchange.Freshness = &ApiSnapshotFreshness{
+			AgeSeconds:    e.Freshness.AgeSeconds,
+			MaxAgeSeconds: e.Freshness.MaxAgeSeconds,
+			Stale:         e.Freshness.Stale}
+	}
+	return &exchange
+}
@@ -0,0 +1,46 @@
+package registrypb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func TestFromExchangeSymbols(t *testing.T) {
+	e := types.ExchangeSymbols{
+		ExchangeID:   1,
+		SnapshotTime: 1546300800000,
+		Symbols: []types.SymbolInfo{
+			{Symbol: "ETHBTC", Status: "TRADING", BaseAsset: "ETH", QuoteAsset: "BTC",
+				Filters: []types.SymbolFilter{{FilterType: "PRICE_FILTER", Params: map[string]string{"minPrice": "0.000001"}}}}}}
+
+	r := FromExchangeSymbols("binance", &e)
+	assert.Equal(t, "binance", r.Exchange)
+	assert.Equal(t, int32(1), r.ExchangeId)
+	assert.Equal(t, e.ContentHash(), r.ContentHash)
+	assert.Equal(t, 1, len(r.Symbols))
+	assert.Equal(t, "ETHBTC", r.Symbols[0].Symbol)
+	assert.Equal(t, "binance:spot:ETH/BTC", r.Symbols[0].CanonicalId)
+	assert.Equal(t, "PRICE_FILTER", r.Symbols[0].Filters[0].FilterType)
+	assert.Equal(t, "0.000001", r.Symbols[0].Filters[0].Params["minPrice"])
+}
+
+func TestFromChangeEvent(t *testing.T) {
+	e := types.ChangeEvent{
+		Type:   "modified",
+		Symbol: "ETHBTC",
+		Before: &types.SymbolInfo{Symbol: "ETHBTC", Status: "TRADING"},
+		After:  &types.SymbolInfo{Symbol: "ETHBTC", Status: "BREAK"},
+		Fields: []types.FieldChange{{Field: "status", Before: "TRADING", After: "BREAK"}}}
+
+	r := FromChangeEvent("binance", &e)
+	assert.Equal(t, "modified", r.Type)
+	assert.Equal(t, "binance", r.Exchange)
+	assert.Equal(t, "TRADING", r.Before.Status)
+	assert.Equal(t, "BREAK", r.After.Status)
+	assert.Equal(t, `"TRADING"`, r.Fields[0].Before)
+	assert.Equal(t, `"BREAK"`, r.Fields[0].After)
+	assert.Nil(t, FromChangeEvent("binance", &types.ChangeEvent{Type: "added"}).Before)
+}
@@ -0,0 +1,5 @@
+// Package registrypb contains the gRPC API of the registry generated from registry.proto
+// and the conversions from the registry types
+package registrypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative registry.proto
@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: registry.proto
+
+package registrypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SymbolsRegistry_GetSymbols_FullMethodName    = "/maketrades.registry.v1.SymbolsRegistry/GetSymbols"
+	SymbolsRegistry_GetSymbol_FullMethodName     = "/maketrades.registry.v1.SymbolsRegistry/GetSymbol"
+	SymbolsRegistry_ListSnapshots_FullMethodName = "/maketrades.registry.v1.SymbolsRegistry/ListSnapshots"
+	SymbolsRegistry_WatchChanges_FullMethodName  = "/maketrades.registry.v1.SymbolsRegistry/WatchChanges"
+)
+
+// SymbolsRegistryClient is the client API for SymbolsRegistry service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SymbolsRegistryClient interface {
+	// GetSymbols returns the symbols of the exchanges passing the filter
+	GetSymbols(ctx context.Context, in *GetSymbolsRequest, opts ...grpc.CallOption) (*GetSymbolsResponse, error)
+	// GetSymbol returns the full information about one symbol
+	GetSymbol(ctx context.Context, in *GetSymbolRequest, opts ...grpc.CallOption) (*GetSymbolResponse, error)
+	// ListSnapshots returns one page of the stored snapshots of an exchange, the latest first
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	// WatchChanges streams the current snapshots followed by the change events of the symbols passing the filter
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (SymbolsRegistry_WatchChangesClient, error)
+}
+
+type symbolsRegistryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSymbolsRegistryClient(cc grpc.ClientConnInterface) SymbolsRegistryClient {
+	return &symbolsRegistryClient{cc}
+}
+
+func (c *symbolsRegistryClient) GetSymbols(ctx context.Context, in *GetSymbolsRequest, opts ...grpc.CallOption) (*GetSymbolsResponse, error) {
+	out := new(GetSymbolsResponse)
+	err := c.cc.Invoke(ctx, SymbolsRegistry_GetSymbols_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *symbolsRegistryClient) GetSymbol(ctx context.Context, in *GetSymbolRequest, opts ...grpc.CallOption) (*GetSymbolResponse, error) {
+	out := new(GetSymbolResponse)
+	err := c.cc.Invoke(ctx, SymbolsRegistry_GetSymbol_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *symbolsRegistryClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, SymbolsRegistry_ListSnapshots_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *symbolsRegistryClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (SymbolsRegistry_WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SymbolsRegistry_ServiceDesc.Streams[0], SymbolsRegistry_WatchChanges_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &symbolsRegistryWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SymbolsRegistry_WatchChangesClient interface {
+	Recv() (*WatchChangesResponse, error)
+	grpc.ClientStream
+}
+
+type symbolsRegistryWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *symbolsRegistryWatchChangesClient) Recv() (*WatchChangesResponse, error) {
+	m := new(WatchChangesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SymbolsRegistryServer is the server API for SymbolsRegistry service.
+// All implementations must embed UnimplementedSymbolsRegistryServer
+// for forward compatibility
+type SymbolsRegistryServer interface {
+	// GetSymbols returns the symbols of the exchanges passing the filter
+	GetSymbols(context.Context, *GetSymbolsRequest) (*GetSymbolsResponse, error)
+	// GetSymbol returns the full information about one symbol
+	GetSymbol(context.Context, *GetSymbolRequest) (*GetSymbolResponse, error)
+	// ListSnapshots returns one page of the stored snapshots of an exchange, the latest first
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	// WatchChanges streams the current snapshots followed by the change events of the symbols passing the filter
+	WatchChanges(*WatchChangesRequest, SymbolsRegistry_WatchChangesServer) error
+	mustEmbedUnimplementedSymbolsRegistryServer()
+}
+
+// UnimplementedSymbolsRegistryServer must be embedded to have forward compatible implementations.
+type UnimplementedSymbolsRegistryServer struct {
+}
+
+func (UnimplementedSymbolsRegistryServer) GetSymbols(context.Context, *GetSymbolsRequest) (*GetSymbolsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSymbols not implemented")
+}
+func (UnimplementedSymbolsRegistryServer) GetSymbol(context.Context, *GetSymbolRequest) (*GetSymbolResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSymbol not implemented")
+}
+func (UnimplementedSymbolsRegistryServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedSymbolsRegistryServer) WatchChanges(*WatchChangesRequest, SymbolsRegistry_WatchChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedSymbolsRegistryServer) mustEmbedUnimplementedSymbolsRegistryServer() {}
+
+// UnsafeSymbolsRegistryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SymbolsRegistryServer will
+// result in compilation errors.
+type UnsafeSymbolsRegistryServer interface {
+	mustEmbedUnimplementedSymbolsRegistryServer()
+}
+
+func RegisterSymbolsRegistryServer(s grpc.ServiceRegistrar, srv SymbolsRegistryServer) {
+	s.RegisterService(&SymbolsRegistry_ServiceDesc, srv)
+}
+
+func _SymbolsRegistry_GetSymbols_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSymbolsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SymbolsRegistryServer).GetSymbols(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SymbolsRegistry_GetSymbols_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SymbolsRegistryServer).GetSymbols(ctx, req.(*GetSymbolsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SymbolsRegistry_GetSymbol_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSymbolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SymbolsRegistryServer).GetSymbol(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SymbolsRegistry_GetSymbol_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SymbolsRegistryServer).GetSymbol(ctx, req.(*GetSymbolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SymbolsRegistry_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SymbolsRegistryServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SymbolsRegistry_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SymbolsRegistryServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SymbolsRegistry_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SymbolsRegistryServer).WatchChanges(m, &symbolsRegistryWatchChangesServer{stream})
+}
+
+type SymbolsRegistry_WatchChangesServer interface {
+	Send(*WatchChangesResponse) error
+	grpc.ServerStream
+}
+
+type symbolsRegistryWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *symbolsRegistryWatchChangesServer) Send(m *WatchChangesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// SymbolsRegistry_ServiceDesc is the grpc.ServiceDesc for SymbolsRegistry service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SymbolsRegistry_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "maketrades.registry.v1.SymbolsRegistry",
+	HandlerType: (*SymbolsRegistryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSymbols",
+			Handler:    _SymbolsRegistry_GetSymbols_Handler,
+		},
+		{
+			MethodName: "GetSymbol",
+			Handler:    _SymbolsRegistry_GetSymbol_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _SymbolsRegistry_ListSnapshots_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _SymbolsRegistry_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "registry.proto",
+}
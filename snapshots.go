@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/db"
+	"github.com/etrubenok/make-trades-registry/formats"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+const (
+	defaultSnapshotsLimit = 100
+	maxSnapshotsLimit     = 1000
+)
+
+// ParseTime parses the time given either in RFC3339 format or as the number of milliseconds since epoch
+func ParseTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// GetTimeParam returns the time from the query parameter or the default value if the parameter is not given
+func GetTimeParam(c *gin.Context, name string, defaultValue time.Time) (time.Time, error) {
+	value := c.Request.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	t, err := ParseTime(value)
+	if err != nil {
+		glog.Errorf("GetTimeParam: cannot parse parameter '%s' value '%s' as time due to error %s", name, value, err)
+		return t, err
+	}
+	return t, nil
+}
+
+// GetLimitParam returns the 'limit' query parameter bounded by maxLimit or the default value if the parameter is not given
+func GetLimitParam(c *gin.Context, defaultLimit int, maxLimit int) (int, error) {
+	value := c.Request.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		e := fmt.Errorf("GetLimitParam: limit '%s' is not a positive integer", value)
+		glog.Error(e)
+		return 0, e
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit, nil
+}
+
+func getExchangeSnapshots(c *gin.Context) {
+	exchange := c.Param("exchange")
+	exchangeIDs, err := GetExchangeIDs([]string{exchange})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	exchangeID := exchangeIDs[0]
+	to, err := GetTimeParam(c, "to", time.Now().UTC())
+	if err != nil {
+		respondError(c, NewInvalidParameterError("to", "'to' must be a time in RFC3339 format or milliseconds since epoch"))
+		return
+	}
+	from, err := GetTimeParam(c, "from", DefaultSnapshotsFrom(to))
+	if err != nil {
+		respondError(c, NewInvalidParameterError("from", "'from' must be a time in RFC3339 format or milliseconds since epoch"))
+		return
+	}
+	cursor, err := GetTimeParam(c, "cursor", to.Add(time.Millisecond))
+	if err != nil {
+		respondError(c, NewInvalidParameterError("cursor", "invalid cursor"))
+		return
+	}
+	limit, err := GetLimitParam(c, defaultSnapshotsLimit, maxSnapshotsLimit)
+	if err != nil {
+		respondError(c, NewInvalidParameterError("limit", "'limit' must be a positive integer"))
+		return
+	}
+
+	r, err := ListExchangeSnapshots(exchange, exchangeID, from, to, cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// DefaultSnapshotsFrom returns the start of the listed snapshots if it is not given: look-back-days before to
+// but no further than the longest range the snapshots are listed for
+func DefaultSnapshotsFrom(to time.Time) time.Time {
+	days := *lookBackDays
+	if days > db.MaxListDays {
+		days = db.MaxListDays
+	}
+	return to.AddDate(0, 0, -days)
+}
+
+// ListExchangeSnapshots returns up to limit snapshots of the exchange taken between from and to, the latest first,
+// starting right before the cursor which is the time of the last snapshot of the previous page.
+// The range between from and to is limited to db.MaxListDays days
+func ListExchangeSnapshots(exchange string, exchangeID int, from, to, cursor time.Time, limit int) (*types.APISnapshotsList, error) {
+	if from.After(to) {
+		return nil, NewInvalidParameterError("from", "'from' must not be after 'to'")
+	}
+	if from.Before(to.AddDate(0, 0, -db.MaxListDays)) {
+		return nil, NewInvalidParameterError("from", fmt.Sprintf("'from' must be within %d days before 'to'", db.MaxListDays))
+	}
+	if cursor.Add(-time.Millisecond).Before(to) {
+		to = cursor.Add(-time.Millisecond)
+	}
+	l := snapshotsCache
+	snapshots, err := l.ListSnapshots(exchangeID, from, to, limit)
+	if err != nil {
+		glog.Errorf("ListExchangeSnapshots: cannot list snapshots of exchange '%s' between %s and %s due to error %s", exchange, from, to, err)
+		return nil, NewStorageError(err)
+	}
+
+	r := types.APISnapshotsList{
+		Exchange:  exchange,
+		Snapshots: make([]types.APISnapshotSummary, len(snapshots))}
+	for i := range snapshots {
+		r.Snapshots[i] = types.ConvertSnapshotSummary(&snapshots[i])
+	}
+	if len(snapshots) == limit {
+		r.NextCursor = strconv.FormatInt(snapshots[len(snapshots)-1].SnapshotTime, 10)
+	}
+	return &r, nil
+}
+
+func getExchangeSnapshot(c *gin.Context) {
+	exchange := c.Param("exchange")
+	exchangeIDs, err := GetExchangeIDs([]string{exchange})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	exchangeID := exchangeIDs[0]
+	format, err := NegotiateFormat(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshotTime, err := ParseTime(c.Param("time"))
+	if err != nil {
+		glog.Errorf("getExchangeSnapshot: cannot parse snapshot time '%s' due to error %s", c.Param("time"), err)
+		respondError(c, NewInvalidParameterError("time", "snapshot time must be in RFC3339 format or milliseconds since epoch"))
+		return
+	}
+
+	l := snapshotsCache
+	snapshot, err := l.LoadSnapshot(exchangeID, snapshotTime)
+	if err != nil {
+		glog.Errorf("getExchangeSnapshot: cannot load snapshot of exchange '%s' at %s due to error %s", exchange, snapshotTime, err)
+		respondError(c, NewStorageError(err))
+		return
+	}
+	r, err := types.ConvertExchangeSymbolsToAPIResponse(&types.ExchangesSymbols{
+		Exchanges: []types.ExchangeSymbols{*snapshot}})
+	if err != nil {
+		glog.Errorf("getExchangeSnapshot: cannot convert to API response due to error %s", err)
+		respondError(c, err)
+		return
+	}
+	// The stored snapshots are never changed
+	if NewCacheValidators(format, r, cacheControlImmutable).CheckNotModified(c) {
+		return
+	}
+	if format != formats.JSON {
+		respondSnapshot(c, format, &r.Exchanges[0])
+		return
+	}
+	c.JSON(http.StatusOK, r.Exchanges[0])
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/db"
+)
+
+func TestParseTimeRFC3339(t *testing.T) {
+	parsed, err := ParseTime("2019-03-04T13:05:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, time.March, 4, 13, 5, 0, 0, time.UTC), parsed)
+}
+
+func TestParseTimeMilliseconds(t *testing.T) {
+	parsed, err := ParseTime("1551704700123")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, time.March, 4, 13, 5, 0, 123000000, time.UTC), parsed)
+}
+
+func TestParseTimeInvalid(t *testing.T) {
+	_, err := ParseTime("2019-03-04")
+	assert.Error(t, err)
+}
+
+func TestGetExchangeSnapshotsInvalidRange(t *testing.T) {
+	for _, query := range []string{"from=2019-03-05T00:00:00Z&to=2019-03-04T00:00:00Z", "from=0&to=2019-03-04T00:00:00Z"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "exchange", Value: "binance"}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/exchanges/binance/snapshots?"+query, nil)
+		getExchangeSnapshots(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestDefaultSnapshotsFrom(t *testing.T) {
+	to := time.Date(2019, time.March, 4, 13, 5, 0, 0, time.UTC)
+	assert.Equal(t, to.AddDate(0, 0, -*lookBackDays), DefaultSnapshotsFrom(to))
+
+	*lookBackDays = 365
+	defer func() { *lookBackDays = 7 }()
+	assert.Equal(t, to.AddDate(0, 0, -db.MaxListDays), DefaultSnapshotsFrom(to))
+}
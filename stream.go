@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+	"github.com/gorilla/websocket"
+
+	"github.com/etrubenok/make-trades-registry/stream"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+var hub *stream.Hub
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     checkOrigin}
+
+// checkOrigin allows the WebSocket connections without an origin, e.g. from the non-browser clients,
+// from the host of the service and from the origins given in the 'allowed-origins' flag
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range strings.Split(*allowedOrigins, ",") {
+		if allowed = strings.TrimSuffix(strings.TrimSpace(allowed), "/"); allowed != "" && strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	glog.Warningf("checkOrigin: rejecting WebSocket connection from origin '%s'", origin)
+	return false
+}
+
+// getLastEventID returns the event ID to resume from, taken from the 'Last-Event-ID' header or the 'last_event_id' query parameter
+func getLastEventID(c *gin.Context) int64 {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Request.URL.Query().Get("last_event_id")
+	}
+	if lastEventID == "" {
+		return 0
+	}
+	id, err := strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil {
+		glog.Warningf("getLastEventID: cannot parse last event id '%s' due to error %s", lastEventID, err)
+		return 0
+	}
+	return id
+}
+
+func streamSymbolsSSE(c *gin.Context) {
+	f, err := ParseFilter(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	subscription, initial := hub.Subscribe(f, getLastEventID(c))
+	defer hub.Unsubscribe(subscription)
+
+	writeEvent := func(w io.Writer, e *types.APIStreamEvent) bool {
+		err := sse.Encode(w, sse.Event{
+			Id:    strconv.FormatInt(e.ID, 10),
+			Event: e.Type,
+			Data:  e})
+		if err != nil {
+			glog.Errorf("streamSymbolsSSE: cannot write event '%d' due to error %s", e.ID, err)
+			return false
+		}
+		return true
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Type", sse.ContentType)
+	c.Stream(func(w io.Writer) bool {
+		for len(initial) > 0 {
+			e := initial[0]
+			initial = initial[1:]
+			if !writeEvent(w, &e) {
+				return false
+			}
+		}
+		select {
+		case e, ok := <-subscription.C:
+			if !ok {
+				return false
+			}
+			return writeEvent(w, &e)
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func streamSymbolsWebSocket(c *gin.Context) {
+	f, err := ParseFilter(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		glog.Errorf("streamSymbolsWebSocket: cannot upgrade connection due to error %s", err)
+		return
+	}
+	defer conn.Close()
+
+	subscription, initial := hub.Subscribe(f, getLastEventID(c))
+	defer hub.Unsubscribe(subscription)
+
+	closed := make(chan struct{})
+	go func() {
+		// The clients are not expected to send anything, reading detects the closed connection
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := range initial {
+		if err := conn.WriteJSON(&initial[i]); err != nil {
+			glog.Errorf("streamSymbolsWebSocket: cannot write event '%d' due to error %s", initial[i].ID, err)
+			return
+		}
+	}
+	for {
+		select {
+		case e, ok := <-subscription.C:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(&e); err != nil {
+				glog.Errorf("streamSymbolsWebSocket: cannot write event '%d' due to error %s", e.ID, err)
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package stream
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/filter"
+	"github.com/etrubenok/make-trades-registry/publishers"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// subscriptionBuffer is the number of events buffered for a subscriber before it is considered too slow and dropped
+const subscriptionBuffer = 1024
+
+// matchEvent returns the event restricted to the filter or nil if nothing of the event passes the filter
+func matchEvent(f *filter.Filter, e *types.APIStreamEvent) *types.APIStreamEvent {
+	switch e.Type {
+	case types.StreamEventTypeChange:
+		symbol := e.Change.After
+		if symbol == nil {
+			symbol = e.Change.Before
+		}
+		if symbol == nil || !f.MatchSymbolInfo(e.Exchange, symbol) {
+			return nil
+		}
+		return e
+	case types.StreamEventTypeSnapshot:
+		if !f.MatchExchange(e.Exchange) {
+			return nil
+		}
+		snapshot := *e.Snapshot
+		snapshot.Symbols = make([]types.APISymbolInfo, 0)
+		for i := range e.Snapshot.Symbols {
+			if f.Match(e.Exchange, &e.Snapshot.Symbols[i]) {
+				snapshot.Symbols = append(snapshot.Symbols, e.Snapshot.Symbols[i])
+			}
+		}
+		r := *e
+		r.Snapshot = &snapshot
+		return &r
+	}
+	return nil
+}
+
+// Subscription type is a registered stream client
+type Subscription struct {
+	C      chan types.APIStreamEvent
+	filter *filter.Filter
+}
+
+// Hub keeps the latest snapshot of every exchange together with the recent change events
+// and fans the new events out to the subscribers. Hub implements publishers.Publisher
+type Hub struct {
+	mutex       sync.Mutex
+	lastID      int64
+	evictedID   int64
+	capacity    int
+	events      []types.APIStreamEvent
+	snapshots   map[string]*types.APIStreamEvent
+	subscribers map[*Subscription]bool
+}
+
+// NewHub instantiates Hub object keeping up to capacity recent change events for resuming clients.
+// The event IDs start from the current time in microseconds so they keep growing across restarts
+// and the clients resuming with an event ID from a previous run get the snapshots again
+func NewHub(capacity int) *Hub {
+	startID := time.Now().UnixNano() / int64(time.Microsecond)
+	h := Hub{
+		lastID:      startID,
+		evictedID:   startID,
+		capacity:    capacity,
+		events:      make([]types.APIStreamEvent, 0),
+		snapshots:   make(map[string]*types.APIStreamEvent),
+		subscribers: make(map[*Subscription]bool)}
+	return &h
+}
+
+// Publish turns the messages into events and sends the change events to the subscribers. The snapshots are kept
+// for the connecting clients, the live subscribers get only the first snapshot of an exchange as they follow
+// the changes afterwards
+func (h *Hub) Publish(messages []publishers.Message) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, m := range messages {
+		h.lastID++
+		e := types.APIStreamEvent{
+			ID:       h.lastID,
+			Exchange: m.Exchange}
+		switch m.Type {
+		case publishers.MessageTypeSnapshot:
+			r, err := types.ConvertExchangeSymbolsToAPIResponse(&types.ExchangesSymbols{
+				Exchanges: []types.ExchangeSymbols{*m.Snapshot}})
+			if err != nil {
+				glog.Errorf("Hub.Publish: cannot convert snapshot of exchange '%s' due to error %s", m.Exchange, err)
+				return err
+			}
+			e.Type = types.StreamEventTypeSnapshot
+			e.Snapshot = &r.Exchanges[0]
+			e.StoredSnapshot = m.Snapshot
+			// Snapshots are not kept in the events buffer: the resuming clients get the changes instead
+			_, sent := h.snapshots[m.Exchange]
+			h.snapshots[m.Exchange] = &e
+			if !sent {
+				h.broadcast(&e)
+			}
+			continue
+		case publishers.MessageTypeChange:
+			e.Type = types.StreamEventTypeChange
+			e.Change = m.Change
+		default:
+			glog.Warningf("Hub.Publish: skipping message of unknown type '%s'", m.Type)
+			continue
+		}
+		h.events = append(h.events, e)
+		if len(h.events) > h.capacity {
+			h.evictedID = h.events[len(h.events)-h.capacity-1].ID
+			h.events = h.events[len(h.events)-h.capacity:]
+		}
+		h.broadcast(&e)
+	}
+	return nil
+}
+
+func (h *Hub) broadcast(e *types.APIStreamEvent) {
+	for s := range h.subscribers {
+		filtered := matchEvent(s.filter, e)
+		if filtered == nil {
+			continue
+		}
+		select {
+		case s.C <- *filtered:
+		default:
+			glog.Warningf("Hub.broadcast: dropping slow subscriber")
+			delete(h.subscribers, s)
+			close(s.C)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns the events the subscriber should get first.
+// If lastEventID is within the kept events, the events after it are returned, otherwise the current snapshots
+func (h *Hub) Subscribe(f *filter.Filter, lastEventID int64) (*Subscription, []types.APIStreamEvent) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s := Subscription{
+		C:      make(chan types.APIStreamEvent, subscriptionBuffer),
+		filter: f}
+	h.subscribers[&s] = true
+
+	initial := make([]types.APIStreamEvent, 0)
+	if h.canResume(lastEventID) {
+		for i := range h.events {
+			if h.events[i].ID <= lastEventID {
+				continue
+			}
+			if e := matchEvent(f, &h.events[i]); e != nil {
+				initial = append(initial, *e)
+			}
+		}
+		return &s, initial
+	}
+
+	exchanges := make([]string, 0, len(h.snapshots))
+	for e := range h.snapshots {
+		exchanges = append(exchanges, e)
+	}
+	sort.Strings(exchanges)
+	for _, exchange := range exchanges {
+		e := *h.snapshots[exchange]
+		e.ID = h.lastID
+		if filtered := matchEvent(f, &e); filtered != nil {
+			initial = append(initial, *filtered)
+		}
+	}
+	return &s, initial
+}
+
+// canResume returns true if no events after lastEventID were evicted from the buffer
+func (h *Hub) canResume(lastEventID int64) bool {
+	return lastEventID >= h.evictedID && lastEventID <= h.lastID && len(h.snapshots) > 0
+}
+
+// Unsubscribe removes the subscriber from the hub
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.subscribers[s] {
+		delete(h.subscribers, s)
+		close(s.C)
+	}
+}
+
+// Close disconnects all the subscribers
+func (h *Hub) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for s := range h.subscribers {
+		delete(h.subscribers, s)
+		close(s.C)
+	}
+	return nil
+}
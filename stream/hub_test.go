@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/etrubenok/make-trades-registry/filter"
+	"github.com/etrubenok/make-trades-registry/publishers"
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/etrubenok/make-trades-types/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFilter(query string) *filter.Filter {
+	values, _ := url.ParseQuery(query)
+	f, _ := filter.ParseQuery(values)
+	return f
+}
+
+func publishTestSnapshot(t *testing.T, h *Hub) {
+	binanceID, err := registry.GetExchangeID("binance")
+	assert.NoError(t, err)
+	err = h.Publish([]publishers.Message{publishers.NewSnapshotMessage("binance", &types.ExchangeSymbols{
+		ExchangeID:   binanceID,
+		SnapshotTime: 1000,
+		Symbols:      []types.SymbolInfo{{Symbol: "BTCUSDT"}, {Symbol: "ETHBTC"}}})})
+	assert.NoError(t, err)
+}
+
+func TestHubSubscribeGetsSnapshot(t *testing.T) {
+	h := NewHub(10)
+	publishTestSnapshot(t, h)
+
+	_, initial := h.Subscribe(testFilter("symbols=binance-ETHBTC"), 0)
+	assert.Len(t, initial, 1)
+	assert.Equal(t, types.StreamEventTypeSnapshot, initial[0].Type)
+	assert.Len(t, initial[0].Snapshot.Symbols, 1)
+	assert.Equal(t, "binance-ETHBTC", initial[0].Snapshot.Symbols[0].Symbol)
+
+	_, initial = h.Subscribe(testFilter("exchanges=bitfinex"), 0)
+	assert.Empty(t, initial)
+}
+
+func TestHubBroadcastAndResume(t *testing.T) {
+	h := NewHub(10)
+	publishTestSnapshot(t, h)
+
+	s, initial := h.Subscribe(testFilter("symbols=ETHBTC"), 0)
+	lastID := initial[0].ID
+	err := h.Publish([]publishers.Message{
+		publishers.NewChangeMessage("binance", &types.ChangeEvent{Type: types.ChangeRemoved, Symbol: "BTCUSDT", Before: &types.SymbolInfo{Symbol: "BTCUSDT"}}),
+		publishers.NewChangeMessage("binance", &types.ChangeEvent{Type: types.ChangeModified, Symbol: "ETHBTC", After: &types.SymbolInfo{Symbol: "ETHBTC"}})})
+	assert.NoError(t, err)
+
+	e := <-s.C
+	assert.Equal(t, "ETHBTC", e.Change.Symbol)
+	assert.Equal(t, lastID+2, e.ID)
+	h.Unsubscribe(s)
+
+	_, initial = h.Subscribe(testFilter(""), lastID)
+	assert.Len(t, initial, 2)
+	assert.Equal(t, "BTCUSDT", initial[0].Change.Symbol)
+}
+
+func TestHubResumeAfterEviction(t *testing.T) {
+	h := NewHub(1)
+	publishTestSnapshot(t, h)
+
+	_, initial := h.Subscribe(testFilter(""), 0)
+	lastID := initial[0].ID
+	err := h.Publish([]publishers.Message{
+		publishers.NewChangeMessage("binance", &types.ChangeEvent{Type: types.ChangeRemoved, Symbol: "BTCUSDT", Before: &types.SymbolInfo{Symbol: "BTCUSDT"}}),
+		publishers.NewChangeMessage("binance", &types.ChangeEvent{Type: types.ChangeModified, Symbol: "ETHBTC", After: &types.SymbolInfo{Symbol: "ETHBTC"}})})
+	assert.NoError(t, err)
+
+	_, initial = h.Subscribe(testFilter(""), lastID)
+	assert.Len(t, initial, 1)
+	assert.Equal(t, types.StreamEventTypeSnapshot, initial[0].Type)
+}
+
+func TestHubBroadcastSnapshot(t *testing.T) {
+	h := NewHub(10)
+	s, initial := h.Subscribe(testFilter("symbols=binance-ETHBTC"), 0)
+	assert.Empty(t, initial)
+
+	publishTestSnapshot(t, h)
+	e := <-s.C
+	assert.Equal(t, types.StreamEventTypeSnapshot, e.Type)
+	assert.Len(t, e.Snapshot.Symbols, 1)
+	assert.Len(t, h.events, 0)
+
+	// The live subscribers follow the changes after the first snapshot
+	publishTestSnapshot(t, h)
+	assert.Len(t, s.C, 0)
+	_, initial = h.Subscribe(testFilter("symbols=binance-ETHBTC"), 0)
+	assert.Len(t, initial, 1)
+}
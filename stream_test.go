@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	*allowedOrigins = "https://app.example.com/, https://other.example.com"
+	defer func() { *allowedOrigins = "" }()
+
+	cases := map[string]bool{
+		"":                          true,
+		"http://registry:8080":      true,
+		"https://app.example.com":   true,
+		"https://other.example.com": true,
+		"https://evil.example.com":  false,
+	}
+	for origin, expected := range cases {
+		r := httptest.NewRequest("GET", "http://registry:8080/stream/symbols/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		assert.Equal(t, expected, checkOrigin(r), origin)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// maxSuggestions is the number of similar symbols suggested when the requested one is not found
+const maxSuggestions = 5
+
+// ParseSymbolID splits the symbol id in format 'exchange-SYMBOL' into the exchange and the native symbol
+func ParseSymbolID(id string) (string, string, error) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("ParseSymbolID: symbol id '%s' is not in format 'exchange-SYMBOL'", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+// SuggestSymbols returns up to limit symbols which are the most similar to the requested one, the most similar first
+func SuggestSymbols(exchange string, symbol string, symbols []types.SymbolInfo, limit int) []string {
+	type candidate struct {
+		symbol   string
+		distance int
+	}
+	query := strings.ToUpper(symbol)
+	candidates := make([]candidate, 0)
+	for _, s := range symbols {
+		name := strings.ToUpper(s.Symbol)
+		distance := levenshtein(query, name)
+		if strings.Contains(name, query) || strings.Contains(query, name) {
+			distance = 0
+		}
+		if distance <= 3 {
+			candidates = append(candidates, candidate{symbol: s.Symbol, distance: distance})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].symbol < candidates[j].symbol
+	})
+
+	suggestions := make([]string, 0, limit)
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		suggestions = append(suggestions, exchange+"-"+candidates[i].symbol)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between the strings a and b
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func getSymbol(c *gin.Context) {
+	// The router does not allow the static /symbols/diff route next to /symbols/:id, so the diff is dispatched from here
+	if c.Param("id") == "diff" {
+		getSymbolsDiff(c)
+		return
+	}
+
+	exchange, symbol, err := ParseSymbolID(c.Param("id"))
+	if err != nil {
+		glog.Errorf("getSymbol: %s", err)
+		respondError(c, NewInvalidParameterError("id", "symbol must be in format 'exchange-SYMBOL'"))
+		return
+	}
+	exchangeIDs, err := GetExchangeIDs([]string{exchange})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	q, err := ParseSnapshotQuery(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	snapshots, err := q.Load(snapshotsCache, exchangeIDs)
+	if err != nil {
+		glog.Errorf("getSymbol: cannot load the snapshot of exchange '%s' due to error %s", exchange, err)
+		respondError(c, err)
+		return
+	}
+
+	snapshot := &snapshots.Exchanges[0]
+	for i := range snapshot.Symbols {
+		if snapshot.Symbols[i].Symbol == symbol {
+			c.JSON(http.StatusOK, types.ConvertSymbolDetail(exchange, snapshot, &snapshot.Symbols[i]))
+			return
+		}
+	}
+	respondError(c, NewNotFoundError("symbol not found", gin.H{
+		"suggestions": SuggestSymbols(exchange, symbol, snapshot.Symbols, maxSuggestions)}))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/etrubenok/make-trades-registry/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSymbolID(t *testing.T) {
+	exchange, symbol, err := ParseSymbolID("bitfinex-tBTCUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, "bitfinex", exchange)
+	assert.Equal(t, "tBTCUSD", symbol)
+
+	_, _, err = ParseSymbolID("BTCUSDT")
+	assert.Error(t, err)
+	_, _, err = ParseSymbolID("binance-")
+	assert.Error(t, err)
+}
+
+func TestSuggestSymbols(t *testing.T) {
+	symbols := []types.SymbolInfo{
+		{Symbol: "BTCUSDT"},
+		{Symbol: "BTCTUSD"},
+		{Symbol: "ETHBTC"},
+		{Symbol: "XRPUSDT"}}
+
+	assert.Equal(t, []string{"binance-BTCUSDT"}, SuggestSymbols("binance", "btcusdt", symbols, 5)[:1])
+	assert.Equal(t, []string{"binance-BTCUSDT", "binance-BTCTUSD"}, SuggestSymbols("binance", "BTCUSD", symbols, 2))
+	assert.Empty(t, SuggestSymbols("binance", "DOGEEUR", symbols, 5))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("ETHBTC", "ETHBTC"))
+	assert.Equal(t, 1, levenshtein("ETHBTC", "ETCBTC"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 6, levenshtein("", "ETHBTC"))
+}
@@ -0,0 +1,40 @@
+package types
+
+const (
+	// AssetRoleBase is the role of an asset which is the base asset of a market
+	AssetRoleBase = "base"
+	// AssetRoleQuote is the role of an asset which is the quote asset of a market
+	AssetRoleQuote = "quote"
+)
+
+// APIAssetMarket type contains information about a market where an asset appears
+type APIAssetMarket struct {
+	Exchange     string `json:"exchange"`
+	Symbol       string `json:"symbol"`
+	NativeSymbol string `json:"native_symbol"`
+	CanonicalID  string `json:"canonical_id"`
+	Type         string `json:"type"`
+	Status       string `json:"status"`
+	Role         string `json:"role"`
+	Asset        string `json:"asset"`
+	Quote        string `json:"quote"`
+}
+
+// APIAssetMarkets type contains all the markets where an asset appears as the base or the quote asset
+type APIAssetMarkets struct {
+	Asset     string           `json:"asset"`
+	Exchanges []string         `json:"exchanges"`
+	Markets   []APIAssetMarket `json:"markets"`
+}
+
+// APIAssetSummary type contains the number of markets where an asset appears on every exchange
+type APIAssetSummary struct {
+	Asset     string         `json:"asset"`
+	Markets   int            `json:"markets"`
+	Exchanges map[string]int `json:"exchanges"`
+}
+
+// APIAssets type contains all the known assets
+type APIAssets struct {
+	Assets []APIAssetSummary `json:"assets"`
+}
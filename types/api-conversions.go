@@ -0,0 +1,32 @@
+package types
+
+const (
+	// SideBuy is the side of a conversion hop buying the base asset of the market for its quote asset
+	SideBuy = "buy"
+	// SideSell is the side of a conversion hop selling the base asset of the market for its quote asset
+	SideSell = "sell"
+)
+
+// APIConversionHop type contains one trade of a conversion route
+type APIConversionHop struct {
+	Exchange     string `json:"exchange"`
+	Symbol       string `json:"symbol"`
+	NativeSymbol string `json:"native_symbol"`
+	CanonicalID  string `json:"canonical_id"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Side         string `json:"side"`
+}
+
+// APIConversionRoute type contains the trades converting one asset into another
+type APIConversionRoute struct {
+	Exchanges []string           `json:"exchanges"`
+	Hops      []APIConversionHop `json:"hops"`
+}
+
+// APIConversionRoutes type contains the shortest routes converting one asset into another
+type APIConversionRoutes struct {
+	From   string               `json:"from"`
+	To     string               `json:"to"`
+	Routes []APIConversionRoute `json:"routes"`
+}
@@ -0,0 +1,32 @@
+package types
+
+const (
+	// ErrorCodeInvalidParameter is the code of the error returned when a request parameter is malformed
+	ErrorCodeInvalidParameter = "invalid_parameter"
+	// ErrorCodeUnknownExchange is the code of the error returned when the requested exchange is not supported
+	ErrorCodeUnknownExchange = "unknown_exchange"
+	// ErrorCodeNotFound is the code of the error returned when the requested data does not exist
+	ErrorCodeNotFound = "not_found"
+	// ErrorCodeStorageUnavailable is the code of the error returned when the backing store cannot serve the request
+	ErrorCodeStorageUnavailable = "storage_unavailable"
+	// ErrorCodeUnauthorized is the code of the error returned when the request does not carry valid credentials
+	ErrorCodeUnauthorized = "unauthorized"
+	// ErrorCodeConflict is the code of the error returned when the request conflicts with the current state
+	ErrorCodeConflict = "conflict"
+	// ErrorCodeNotAcceptable is the code of the error returned when none of the accepted response formats is supported
+	ErrorCodeNotAcceptable = "not_acceptable"
+	// ErrorCodeInternal is the code of the error returned on unexpected failures
+	ErrorCodeInternal = "internal_error"
+)
+
+// APIError type contains information about an error of an API request
+type APIError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// APIErrorResponse type is the body of all the error responses of the API
+type APIErrorResponse struct {
+	Error APIError `json:"error"`
+}
@@ -0,0 +1,31 @@
+package types
+
+const (
+	// HealthStatusOK is the status of a passed check
+	HealthStatusOK = "ok"
+	// HealthStatusFail is the status of a failed check
+	HealthStatusFail = "fail"
+	// HealthStatusDegraded is the status of the registry which is ready while some of the checks failed
+	HealthStatusDegraded = "degraded"
+)
+
+// APICheckResult type contains the outcome of one health check
+type APICheckResult struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// APISnapshotCheckResult type contains the outcome of the freshness check of the latest snapshot of an exchange
+type APISnapshotCheckResult struct {
+	APICheckResult
+	SnapshotTime  int64   `json:"snapshot_time,omitempty"`
+	AgeSeconds    float64 `json:"age_seconds"`
+	MaxAgeSeconds float64 `json:"max_age_seconds"`
+}
+
+// APIReadinessReport type contains the outcomes of the checks of the dependencies and the snapshots of every exchange
+type APIReadinessReport struct {
+	Status    string                            `json:"status"`
+	Storage   APICheckResult                    `json:"storage"`
+	Snapshots map[string]APISnapshotCheckResult `json:"snapshots"`
+}
@@ -0,0 +1,37 @@
+package types
+
+// APIMarketVenue type contains information about a market on one exchange
+type APIMarketVenue struct {
+	Exchange       string `json:"exchange"`
+	Symbol         string `json:"symbol"`
+	NativeSymbol   string `json:"native_symbol"`
+	CanonicalID    string `json:"canonical_id"`
+	Status         string `json:"status"`
+	AssetPrecision int64  `json:"asset_precision"`
+	QuotePrecision int64  `json:"quote_precision"`
+}
+
+// APICommonMarket type contains a market given by the normalised base and quote assets and its venues
+type APICommonMarket struct {
+	Base   string           `json:"base"`
+	Quote  string           `json:"quote"`
+	Venues []APIMarketVenue `json:"venues"`
+}
+
+// APICommonMarkets type contains the markets listed on several exchanges
+type APICommonMarkets struct {
+	Exchanges []string          `json:"exchanges"`
+	Markets   []APICommonMarket `json:"markets"`
+}
+
+// ConvertMarketVenue converts the symbol of the exchange in DB format into the venue of a market in API format
+func ConvertMarketVenue(exchange string, symbolInfo *SymbolInfo) APIMarketVenue {
+	return APIMarketVenue{
+		Exchange:       exchange,
+		Symbol:         exchange + "-" + symbolInfo.Symbol,
+		NativeSymbol:   symbolInfo.Symbol,
+		CanonicalID:    NewInstrumentID(exchange, symbolInfo).String(),
+		Status:         symbolInfo.Status,
+		AssetPrecision: symbolInfo.BaseAssetPrecision,
+		QuotePrecision: symbolInfo.QuotePrecision}
+}
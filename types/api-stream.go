@@ -0,0 +1,19 @@
+package types
+
+const (
+	// StreamEventTypeSnapshot is the type of the event carrying the current snapshot of symbols of an exchange
+	StreamEventTypeSnapshot = "snapshot"
+	// StreamEventTypeChange is the type of the event carrying one change of a symbol
+	StreamEventTypeChange = "change"
+)
+
+// APIStreamEvent type is one event delivered to the stream clients
+type APIStreamEvent struct {
+	ID       int64               `json:"id"`
+	Type     string              `json:"type"`
+	Exchange string              `json:"exchange"`
+	Snapshot *APIExchangeSymbols `json:"snapshot,omitempty"`
+	Change   *ChangeEvent        `json:"change,omitempty"`
+	// StoredSnapshot is the snapshot in DB format the API one is converted from, it is sent by the gRPC stream
+	StoredSnapshot *ExchangeSymbols `json:"-"`
+}
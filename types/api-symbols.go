@@ -7,22 +7,37 @@ import (
 
 // APISymbolInfo type contains information about one symbol
 type APISymbolInfo struct {
-	Symbol string `json:"symbol"`
-	Status string `json:"status"`
-	Asset  string `json:"asset"`
-	Quote  string `json:"quote"`
+	Symbol       string `json:"symbol"`
+	NativeSymbol string `json:"native_symbol"`
+	CanonicalID  string `json:"canonical_id"`
+	Status       string `json:"status"`
+	Type         string `json:"type"`
+	Asset        string `json:"asset"`
+	Quote        string `json:"quote"`
 }
 
 // APIExchangeSymbols type contains information about symbols of an exchange
 type APIExchangeSymbols struct {
-	Exchange     string          `json:"exchange"`
-	SnapshotTime int64           `json:"snapshot_time"`
-	Symbols      []APISymbolInfo `json:"symbols"`
+	Exchange     string                `json:"exchange"`
+	SnapshotDate string                `json:"snapshot_date"`
+	SnapshotTime int64                 `json:"snapshot_time"`
+	ContentHash  string                `json:"content_hash"`
+	Freshness    *APISnapshotFreshness `json:"freshness,omitempty"`
+	Symbols      []APISymbolInfo       `json:"symbols"`
+}
+
+// APISnapshotFreshness type tells how old the latest snapshot of an exchange is. The snapshot is stale
+// if it is older than the maximum age configured for the exchange, e.g. because the exchange cannot be fetched
+type APISnapshotFreshness struct {
+	AgeSeconds    float64 `json:"age_seconds"`
+	MaxAgeSeconds float64 `json:"max_age_seconds"`
+	Stale         bool    `json:"stale"`
 }
 
 // APIExchangesSymbols type contains information about symbols of several exchanges
 type APIExchangesSymbols struct {
-	Exchanges []APIExchangeSymbols `json:"exchanges"`
+	Exchanges  []APIExchangeSymbols `json:"exchanges"`
+	NextCursor string               `json:"next_cursor,omitempty"`
 }
 
 // ConvertExchangeSymbolsToAPIResponse converts the given exchangesSymbols in DB format into API responce format
@@ -50,7 +65,9 @@ func convertExchangeSymbols(exchangeSymbols *ExchangeSymbols) (*APIExchangeSymbo
 	}
 	e := APIExchangeSymbols{
 		Exchange:     exchange,
+		SnapshotDate: exchangeSymbols.SnapshotDate(),
 		SnapshotTime: exchangeSymbols.SnapshotTime,
+		ContentHash:  exchangeSymbols.ContentHash(),
 		Symbols:      make([]APISymbolInfo, len(exchangeSymbols.Symbols))}
 
 	for i, s := range exchangeSymbols.Symbols {
@@ -67,9 +84,64 @@ func convertExchangeSymbols(exchangeSymbols *ExchangeSymbols) (*APIExchangeSymbo
 
 func convertSymbolInfo(exchange string, symbolInfo *SymbolInfo) (*APISymbolInfo, error) {
 	s := APISymbolInfo{
-		Symbol: exchange + "-" + symbolInfo.Symbol,
-		Status: symbolInfo.Status,
-		Asset:  symbolInfo.BaseAsset,
-		Quote:  symbolInfo.QuoteAsset}
+		Symbol:       exchange + "-" + symbolInfo.Symbol,
+		NativeSymbol: symbolInfo.Symbol,
+		CanonicalID:  NewInstrumentID(exchange, symbolInfo).String(),
+		Status:       symbolInfo.Status,
+		Type:         symbolInfo.Type,
+		Asset:        symbolInfo.BaseAsset,
+		Quote:        symbolInfo.QuoteAsset}
 	return &s, nil
 }
+
+// APISymbolDetail type contains the full information about one symbol
+type APISymbolDetail struct {
+	Symbol         string         `json:"symbol"`
+	Exchange       string         `json:"exchange"`
+	NativeSymbol   string         `json:"native_symbol"`
+	CanonicalID    string         `json:"canonical_id"`
+	Status         string         `json:"status"`
+	Type           string         `json:"type"`
+	Asset          string         `json:"asset"`
+	NativeAsset    string         `json:"native_asset"`
+	AssetPrecision int64          `json:"asset_precision"`
+	Quote          string         `json:"quote"`
+	NativeQuote    string         `json:"native_quote"`
+	QuotePrecision int64          `json:"quote_precision"`
+	OrderTypes     []string       `json:"order_types"`
+	IcebergAllowed bool           `json:"iceberg_allowed"`
+	Filters        []SymbolFilter `json:"filters"`
+	SnapshotDate   string         `json:"snapshot_date"`
+	SnapshotTime   int64          `json:"snapshot_time"`
+}
+
+// ConvertSymbolDetail converts the symbol of the snapshot in DB format into the full symbol information in API format
+func ConvertSymbolDetail(exchange string, exchangeSymbols *ExchangeSymbols, symbolInfo *SymbolInfo) *APISymbolDetail {
+	d := APISymbolDetail{
+		Symbol:         exchange + "-" + symbolInfo.Symbol,
+		Exchange:       exchange,
+		NativeSymbol:   symbolInfo.Symbol,
+		CanonicalID:    NewInstrumentID(exchange, symbolInfo).String(),
+		Status:         symbolInfo.Status,
+		Type:           symbolInfo.Type,
+		Asset:          symbolInfo.BaseAsset,
+		NativeAsset:    symbolInfo.NativeBaseAsset,
+		AssetPrecision: symbolInfo.BaseAssetPrecision,
+		Quote:          symbolInfo.QuoteAsset,
+		NativeQuote:    symbolInfo.NativeQuoteAsset,
+		QuotePrecision: symbolInfo.QuotePrecision,
+		OrderTypes:     symbolInfo.OrderTypes,
+		IcebergAllowed: symbolInfo.IcebergAllowed,
+		Filters:        symbolInfo.Filters,
+		SnapshotDate:   exchangeSymbols.SnapshotDate(),
+		SnapshotTime:   exchangeSymbols.SnapshotTime}
+	return &d
+}
+
+// APIInstrumentLookup type contains the native symbol of an exchange and its canonical identifier
+type APIInstrumentLookup struct {
+	Exchange     string `json:"exchange"`
+	Symbol       string `json:"symbol"`
+	NativeSymbol string `json:"native_symbol"`
+	CanonicalID  string `json:"canonical_id"`
+}
@@ -0,0 +1,53 @@
+package types
+
+const (
+	// ChangeAdded is the type of the change event for a symbol which appeared on an exchange
+	ChangeAdded = "added"
+	// ChangeRemoved is the type of the change event for a symbol which disappeared from an exchange
+	ChangeRemoved = "removed"
+	// ChangeModified is the type of the change event for a symbol which details have changed
+	ChangeModified = "modified"
+)
+
+// FieldChange type contains the before and after values of one field of a symbol
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// SymbolModification type contains information about a symbol which details have changed
+type SymbolModification struct {
+	Symbol string        `json:"symbol"`
+	Before SymbolInfo    `json:"before"`
+	After  SymbolInfo    `json:"after"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// SymbolsDiff type contains the differences between two snapshots of symbols of an exchange
+type SymbolsDiff struct {
+	ExchangeID       int                  `json:"exchange_id"`
+	FromSnapshotTime int64                `json:"from_snapshot_time"`
+	ToSnapshotTime   int64                `json:"to_snapshot_time"`
+	Added            []SymbolInfo         `json:"added"`
+	Removed          []SymbolInfo         `json:"removed"`
+	Modified         []SymbolModification `json:"modified"`
+}
+
+// ChangeEvent type contains information about one change of a symbol between two snapshots
+type ChangeEvent struct {
+	Type                 string        `json:"type"`
+	ExchangeID           int           `json:"exchange_id"`
+	Symbol               string        `json:"symbol"`
+	SnapshotTime         int64         `json:"snapshot_time"`
+	PreviousSnapshotTime int64         `json:"previous_snapshot_time"`
+	Before               *SymbolInfo   `json:"before,omitempty"`
+	After                *SymbolInfo   `json:"after,omitempty"`
+	Fields               []FieldChange `json:"fields,omitempty"`
+}
+
+// APISymbolsDiff type contains the differences between two snapshots of symbols of an exchange in API format
+type APISymbolsDiff struct {
+	Exchange string `json:"exchange"`
+	*SymbolsDiff
+}
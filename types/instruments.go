@@ -0,0 +1,103 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// InstrumentID type is the canonical identifier of an instrument, the same for the same market on every exchange
+// up to the exchange name. Its string form is 'exchange:type:BASE/QUOTE', or 'exchange:type:BASE' for the instruments
+// without a quote asset, e.g. 'binance:spot:BTC/USDT' or 'bitfinex:funding:USD'
+type InstrumentID struct {
+	Exchange string
+	Type     string
+	Base     string
+	Quote    string
+}
+
+// NewInstrumentID creates the canonical identifier of the symbol of the exchange.
+// The symbols stored before the instrument types were introduced are spot pairs
+func NewInstrumentID(exchange string, symbolInfo *SymbolInfo) InstrumentID {
+	instrumentType := symbolInfo.Type
+	if instrumentType == "" {
+		instrumentType = InstrumentTypeSpot
+	}
+	return InstrumentID{
+		Exchange: strings.ToLower(exchange),
+		Type:     instrumentType,
+		Base:     strings.ToUpper(symbolInfo.BaseAsset),
+		Quote:    strings.ToUpper(symbolInfo.QuoteAsset)}
+}
+
+// ParseInstrumentID parses the canonical identifier in format 'exchange:type:BASE/QUOTE' or 'exchange:type:BASE'
+func ParseInstrumentID(id string) (InstrumentID, error) {
+	parts := strings.SplitN(id, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return InstrumentID{}, fmt.Errorf("ParseInstrumentID: id '%s' is not in format 'exchange:type:BASE/QUOTE'", id)
+	}
+	assets := strings.SplitN(parts[2], "/", 2)
+	i := InstrumentID{
+		Exchange: strings.ToLower(parts[0]),
+		Type:     strings.ToLower(parts[1]),
+		Base:     strings.ToUpper(assets[0])}
+	if len(assets) == 2 {
+		if assets[0] == "" || assets[1] == "" {
+			return InstrumentID{}, fmt.Errorf("ParseInstrumentID: id '%s' is not in format 'exchange:type:BASE/QUOTE'", id)
+		}
+		i.Quote = strings.ToUpper(assets[1])
+	}
+	return i, nil
+}
+
+// String returns the canonical identifier in format 'exchange:type:BASE/QUOTE' or 'exchange:type:BASE'
+func (i InstrumentID) String() string {
+	if i.Quote == "" {
+		return fmt.Sprintf("%s:%s:%s", i.Exchange, i.Type, i.Base)
+	}
+	return fmt.Sprintf("%s:%s:%s/%s", i.Exchange, i.Type, i.Base, i.Quote)
+}
+
+// InstrumentIndex type maps the native symbols of an exchange to their canonical identifiers and back
+type InstrumentIndex struct {
+	exchange    string
+	toCanonical map[string]string
+	toNative    map[string]string
+}
+
+// NewInstrumentIndex creates the index of the symbols of the snapshot of the exchange
+func NewInstrumentIndex(exchange string, exchangeSymbols *ExchangeSymbols) *InstrumentIndex {
+	index := InstrumentIndex{
+		exchange:    exchange,
+		toCanonical: make(map[string]string, len(exchangeSymbols.Symbols)),
+		toNative:    make(map[string]string, len(exchangeSymbols.Symbols))}
+	for i := range exchangeSymbols.Symbols {
+		s := &exchangeSymbols.Symbols[i]
+		canonical := NewInstrumentID(exchange, s).String()
+		index.toCanonical[s.Symbol] = canonical
+		if native, ok := index.toNative[canonical]; ok {
+			glog.Warningf("NewInstrumentIndex: symbols '%s' and '%s' of exchange '%s' have the same canonical id '%s'",
+				native, s.Symbol, exchange, canonical)
+			continue
+		}
+		index.toNative[canonical] = s.Symbol
+	}
+	return &index
+}
+
+// Canonical returns the canonical identifier of the native symbol
+func (x *InstrumentIndex) Canonical(native string) (string, bool) {
+	canonical, ok := x.toCanonical[native]
+	return canonical, ok
+}
+
+// Native returns the native symbol of the canonical identifier
+func (x *InstrumentIndex) Native(canonical string) (string, bool) {
+	id, err := ParseInstrumentID(canonical)
+	if err != nil {
+		return "", false
+	}
+	native, ok := x.toNative[id.String()]
+	return native, ok
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInstrumentID(t *testing.T) {
+	assert.Equal(t, "binance:spot:BTC/USDT",
+		NewInstrumentID("binance", &SymbolInfo{Symbol: "BTCUSDT", Type: InstrumentTypeSpot, BaseAsset: "BTC", QuoteAsset: "USDT"}).String())
+	assert.Equal(t, "bitfinex:spot:BTC/USD",
+		NewInstrumentID("bitfinex", &SymbolInfo{Symbol: "tBTCUSD", Type: InstrumentTypeSpot, BaseAsset: "BTC", QuoteAsset: "USD"}).String())
+	assert.Equal(t, "bitfinex:funding:USD",
+		NewInstrumentID("bitfinex", &SymbolInfo{Symbol: "fUSD", Type: InstrumentTypeFunding, BaseAsset: "USD"}).String())
+	assert.Equal(t, "binance:spot:ETH/BTC",
+		NewInstrumentID("binance", &SymbolInfo{Symbol: "ETHBTC", BaseAsset: "eth", QuoteAsset: "btc"}).String())
+}
+
+func TestParseInstrumentID(t *testing.T) {
+	id, err := ParseInstrumentID("binance:spot:btc/usdt")
+	assert.NoError(t, err)
+	assert.Equal(t, InstrumentID{Exchange: "binance", Type: "spot", Base: "BTC", Quote: "USDT"}, id)
+
+	id, err = ParseInstrumentID("bitfinex:funding:USD")
+	assert.NoError(t, err)
+	assert.Equal(t, InstrumentID{Exchange: "bitfinex", Type: "funding", Base: "USD"}, id)
+
+	for _, invalid := range []string{"", "binance", "binance:spot", "binance:spot:/USDT", "binance:spot:BTC/", "binance-BTCUSDT"} {
+		_, err := ParseInstrumentID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestInstrumentIndex(t *testing.T) {
+	index := NewInstrumentIndex("bitfinex", &ExchangeSymbols{
+		Symbols: []SymbolInfo{
+			{Symbol: "tBTCUSD", Type: InstrumentTypeSpot, BaseAsset: "BTC", QuoteAsset: "USD"},
+			{Symbol: "fUSD", Type: InstrumentTypeFunding, BaseAsset: "USD"}}})
+
+	canonical, ok := index.Canonical("tBTCUSD")
+	assert.True(t, ok)
+	assert.Equal(t, "bitfinex:spot:BTC/USD", canonical)
+
+	native, ok := index.Native("bitfinex:spot:btc/usd")
+	assert.True(t, ok)
+	assert.Equal(t, "tBTCUSD", native)
+
+	native, ok = index.Native("bitfinex:funding:USD")
+	assert.True(t, ok)
+	assert.Equal(t, "fUSD", native)
+
+	_, ok = index.Canonical("tETHUSD")
+	assert.False(t, ok)
+	_, ok = index.Native("bitfinex:spot:ETH/USD")
+	assert.False(t, ok)
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// APISnapshotSummary type contains information about one stored snapshot of symbols of an exchange
+type APISnapshotSummary struct {
+	SnapshotDate string `json:"snapshot_date"`
+	SnapshotTime int64  `json:"snapshot_time"`
+	SymbolsCount int    `json:"symbols_count"`
+	ContentHash  string `json:"content_hash"`
+}
+
+// APISnapshotsList type contains one page of the stored snapshots of an exchange
+type APISnapshotsList struct {
+	Exchange   string               `json:"exchange"`
+	Snapshots  []APISnapshotSummary `json:"snapshots"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// SnapshotDate returns the date of the day partition of the snapshot in format 'yyyy-mm-dd'
+func (e *ExchangeSymbols) SnapshotDate() string {
+	return fmt.Sprintf("%04d-%02d-%02d", e.Year, e.Month, e.Day)
+}
+
+// ContentHash returns the SHA-256 hash of the symbols of the snapshot. The hash does not depend on
+// the order of the symbols and the snapshot time, so equal snapshots taken at different times have equal hashes
+func (e *ExchangeSymbols) ContentHash() string {
+	symbols := make([]SymbolInfo, len(e.Symbols))
+	copy(symbols, e.Symbols)
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+
+	// Marshalling of the plain struct slice cannot fail
+	data, _ := json.Marshal(symbols)
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// ConvertSnapshotSummary converts the snapshot in DB format into its summary in API format
+func ConvertSnapshotSummary(exchangeSymbols *ExchangeSymbols) APISnapshotSummary {
+	return APISnapshotSummary{
+		SnapshotDate: exchangeSymbols.SnapshotDate(),
+		SnapshotTime: exchangeSymbols.SnapshotTime,
+		SymbolsCount: len(exchangeSymbols.Symbols),
+		ContentHash:  exchangeSymbols.ContentHash()}
+}
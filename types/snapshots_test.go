@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentHashIgnoresOrderAndTime(t *testing.T) {
+	a := ExchangeSymbols{
+		SnapshotTime: 1000,
+		Symbols:      []SymbolInfo{{Symbol: "BTCUSDT"}, {Symbol: "ETHBTC"}}}
+	b := ExchangeSymbols{
+		SnapshotTime: 2000,
+		Symbols:      []SymbolInfo{{Symbol: "ETHBTC"}, {Symbol: "BTCUSDT"}}}
+	assert.Equal(t, a.ContentHash(), b.ContentHash())
+
+	b.Symbols[0].Status = "BREAK"
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+}
+
+func TestConvertSnapshotSummary(t *testing.T) {
+	s := ConvertSnapshotSummary(&ExchangeSymbols{
+		Year:         2019,
+		Month:        3,
+		Day:          4,
+		SnapshotTime: 1551704700000,
+		Symbols:      []SymbolInfo{{Symbol: "BTCUSDT"}}})
+	assert.Equal(t, "2019-03-04", s.SnapshotDate)
+	assert.Equal(t, 1, s.SymbolsCount)
+	assert.Len(t, s.ContentHash, 64)
+}
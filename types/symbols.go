@@ -1,24 +1,44 @@
 package types
 
+const (
+	// InstrumentTypeSpot is the type of the spot trading pairs
+	InstrumentTypeSpot = "spot"
+	// InstrumentTypeFunding is the type of the margin funding currencies
+	InstrumentTypeFunding = "funding"
+)
+
+// StatusTrading is the status of the symbols open for trading
+const StatusTrading = "TRADING"
+
 // SymbolInfo type contains information about one symbol
 type SymbolInfo struct {
-	Symbol             string   `json:"symbol" cql:"symbol"`
-	Status             string   `json:"status" cql:"status"`
-	BaseAsset          string   `json:"baseAsset" cql:"asset"`
-	BaseAssetPrecision int64    `json:"baseAssetPrecision" cql:"asset_precision"`
-	QuoteAsset         string   `json:"quoteAsset" cql:"quote"`
-	QuotePrecision     int64    `json:"quotePrecision" cql:"quote_precision"`
-	OrderTypes         []string `json:"quoteTypes" cql:"order_types"`
-	IcebergAllowed     bool     `json:"icebergAllowed" cql:"iceberg_allowed"`
+	Symbol             string         `json:"symbol" cql:"symbol"`
+	Status             string         `json:"status" cql:"status"`
+	Type               string         `json:"type" cql:"type"`
+	BaseAsset          string         `json:"baseAsset" cql:"asset"`
+	BaseAssetPrecision int64          `json:"baseAssetPrecision" cql:"asset_precision"`
+	QuoteAsset         string         `json:"quoteAsset" cql:"quote"`
+	QuotePrecision     int64          `json:"quotePrecision" cql:"quote_precision"`
+	NativeBaseAsset    string         `json:"nativeBaseAsset" cql:"native_asset"`
+	NativeQuoteAsset   string         `json:"nativeQuoteAsset" cql:"native_quote"`
+	OrderTypes         []string       `json:"quoteTypes" cql:"order_types"`
+	IcebergAllowed     bool           `json:"icebergAllowed" cql:"iceberg_allowed"`
+	Filters            []SymbolFilter `json:"filters" cql:"filters"`
+}
+
+// SymbolFilter type contains one trading rule of a symbol, e.g. the price or the lot size limits
+type SymbolFilter struct {
+	FilterType string            `json:"filterType" cql:"filter_type"`
+	Params     map[string]string `json:"params" cql:"params"`
 }
 
 // ExchangeSymbols type contains information about symbols of an exchange
 type ExchangeSymbols struct {
-	Year         int          `cql:"year"`
-	Month        int          `cql:"month"`
-	Day          int          `cql:"day"`
-	ExchangeID   int          `cql:"exchange_id"`
-	SnapshotTime int64        `cql:"snapshot_time"`
+	Year         int          `json:"year" cql:"year"`
+	Month        int          `json:"month" cql:"month"`
+	Day          int          `json:"day" cql:"day"`
+	ExchangeID   int          `json:"exchange_id" cql:"exchange_id"`
+	SnapshotTime int64        `json:"snapshot_time" cql:"snapshot_time"`
 	Symbols      []SymbolInfo `json:"symbols" cql:"symbols"`
 }
 
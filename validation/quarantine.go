@@ -0,0 +1,137 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// Entry type is one quarantined snapshot with the violated rules
+type Entry struct {
+	Exchange      string                 `json:"exchange"`
+	SnapshotTime  int64                  `json:"snapshot_time"`
+	QuarantinedAt int64                  `json:"quarantined_at"`
+	Violations    []Violation            `json:"violations"`
+	Snapshot      *types.ExchangeSymbols `json:"snapshot,omitempty"`
+}
+
+// Quarantine is an interface of the storage of the rejected snapshots
+type Quarantine interface {
+	Put(entry *Entry) error
+	// List returns the quarantined snapshots without their symbols, the latest first
+	List() ([]Entry, error)
+}
+
+// summary returns the entry without the snapshot
+func (e Entry) summary() Entry {
+	e.Snapshot = nil
+	return e
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].QuarantinedAt > entries[j].QuarantinedAt })
+}
+
+// MemoryQuarantine is an implementation of Quarantine interface keeping the recent entries in memory.
+// The entries are kept without their snapshots to bound the memory used
+type MemoryQuarantine struct {
+	limit   int
+	mutex   sync.Mutex
+	entries []Entry
+}
+
+// NewMemoryQuarantine instantiates object of Quarantine interface (MemoryQuarantine class) keeping up to limit entries
+func NewMemoryQuarantine(limit int) Quarantine {
+	q := MemoryQuarantine{
+		limit:   limit,
+		entries: make([]Entry, 0)}
+	return &q
+}
+
+// Put stores the entry without its snapshot dropping the oldest one if the quarantine is full
+func (q *MemoryQuarantine) Put(entry *Entry) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.entries = append(q.entries, entry.summary())
+	if len(q.entries) > q.limit {
+		q.entries = q.entries[len(q.entries)-q.limit:]
+	}
+	return nil
+}
+
+// List returns the quarantined snapshots without their symbols, the latest first
+func (q *MemoryQuarantine) List() ([]Entry, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	r := make([]Entry, len(q.entries))
+	copy(r, q.entries)
+	sortEntries(r)
+	return r, nil
+}
+
+// FileQuarantine is an implementation of Quarantine interface writing every entry into a JSON file in a directory
+type FileQuarantine struct {
+	dir string
+}
+
+// NewFileQuarantine instantiates object of Quarantine interface (FileQuarantine class). The directory is created if missing
+func NewFileQuarantine(dir string) (Quarantine, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		glog.Errorf("NewFileQuarantine: cannot create directory '%s' due to error %s", dir, err)
+		return nil, err
+	}
+	q := FileQuarantine{dir: dir}
+	return &q, nil
+}
+
+// Put writes the entry into the file named after the exchange and the snapshot time
+func (q *FileQuarantine) Put(entry *Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		glog.Errorf("FileQuarantine.Put: cannot marshal the entry due to error %s", err)
+		return err
+	}
+	path := filepath.Join(q.dir, fmt.Sprintf("%s-%d.json", entry.Exchange, entry.SnapshotTime))
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		glog.Errorf("FileQuarantine.Put: cannot write file '%s' due to error %s", path, err)
+		return err
+	}
+	return nil
+}
+
+// List reads the quarantined snapshots from the directory, the latest first
+func (q *FileQuarantine) List() ([]Entry, error) {
+	files, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		glog.Errorf("FileQuarantine.List: cannot read directory '%s' due to error %s", q.dir, err)
+		return nil, err
+	}
+	r := make([]Entry, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(q.dir, f.Name()))
+		if err != nil {
+			glog.Errorf("FileQuarantine.List: cannot read file '%s' due to error %s", f.Name(), err)
+			return nil, err
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			glog.Errorf("FileQuarantine.List: cannot parse file '%s' due to error %s", f.Name(), err)
+			continue
+		}
+		r = append(r, e.summary())
+	}
+	sortEntries(r)
+	return r, nil
+}
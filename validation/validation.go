@@ -0,0 +1,211 @@
+// Package validation guards the registry against the suspicious snapshots, e.g. the empty ones produced
+// by a bad exchange response, which would otherwise replace the good ones
+package validation
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/etrubenok/make-trades-types/registry"
+	"github.com/golang/glog"
+
+	"github.com/etrubenok/make-trades-registry/metrics"
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+// The rules a snapshot can violate
+const (
+	RuleMinSymbols     = "min_symbols"
+	RuleMaxRemoved     = "max_removed"
+	RuleRequiredFields = "required_fields"
+	RuleDuplicates     = "duplicate_symbols"
+)
+
+// Rules type contains the thresholds of the checks of a new snapshot
+type Rules struct {
+	// MinSymbols is the minimum number of symbols in a snapshot
+	MinSymbols int
+	// MaxRemovedPercent is the maximum percentage of the symbols of the previous snapshot missing in the new one
+	MaxRemovedPercent float64
+	// ConfirmRemovals is the number of the consecutive snapshots agreeing with each other after which the removal
+	// of more symbols than MaxRemovedPercent is accepted as a real delisting, 0 never accepts it
+	ConfirmRemovals int
+}
+
+// DefaultRules are the rules used unless configured otherwise
+var DefaultRules = Rules{
+	MinSymbols:        10,
+	MaxRemovedPercent: 20,
+	ConfirmRemovals:   3}
+
+// Violation type contains the rule a snapshot violates and the details
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Validate returns the violations of the rules by the snapshot. previous is the last accepted snapshot
+// of the exchange or nil if there is none
+func Validate(snapshot *types.ExchangeSymbols, previous *types.ExchangeSymbols, rules Rules) []Violation {
+	violations := make([]Violation, 0)
+	if len(snapshot.Symbols) < rules.MinSymbols {
+		violations = append(violations, Violation{
+			Rule:    RuleMinSymbols,
+			Message: fmt.Sprintf("%d symbols, at least %d are required", len(snapshot.Symbols), rules.MinSymbols)})
+	}
+
+	current := make(map[string]bool, len(snapshot.Symbols))
+	duplicates := make([]string, 0)
+	missing := make([]string, 0)
+	for i := range snapshot.Symbols {
+		s := &snapshot.Symbols[i]
+		if current[s.Symbol] {
+			duplicates = append(duplicates, s.Symbol)
+		}
+		current[s.Symbol] = true
+		if fields := missingFields(s); len(fields) > 0 {
+			missing = append(missing, fmt.Sprintf("%s %v", s.Symbol, fields))
+		}
+	}
+	if len(duplicates) > 0 {
+		violations = append(violations, Violation{
+			Rule:    RuleDuplicates,
+			Message: fmt.Sprintf("%d symbols are listed more than once, e.g. %s", len(duplicates), duplicates[0])})
+	}
+	if len(missing) > 0 {
+		violations = append(violations, Violation{
+			Rule:    RuleRequiredFields,
+			Message: fmt.Sprintf("%d symbols miss required fields, e.g. %s", len(missing), missing[0])})
+	}
+
+	if previous != nil && len(previous.Symbols) > 0 {
+		removed := 0
+		for i := range previous.Symbols {
+			if !current[previous.Symbols[i].Symbol] {
+				removed++
+			}
+		}
+		percent := float64(removed) * 100 / float64(len(previous.Symbols))
+		if percent > rules.MaxRemovedPercent {
+			violations = append(violations, Violation{
+				Rule: RuleMaxRemoved,
+				Message: fmt.Sprintf("%d of %d symbols (%.1f%%) of the previous snapshot are removed, at most %.1f%% are allowed",
+					removed, len(previous.Symbols), percent, rules.MaxRemovedPercent)})
+		}
+	}
+	return violations
+}
+
+// missingFields returns the names of the required fields which are empty. The funding instruments have no quote asset
+func missingFields(s *types.SymbolInfo) []string {
+	fields := make([]string, 0)
+	if s.Symbol == "" {
+		fields = append(fields, "symbol")
+	}
+	if s.Status == "" {
+		fields = append(fields, "status")
+	}
+	if s.BaseAsset == "" {
+		fields = append(fields, "base_asset")
+	}
+	if s.QuoteAsset == "" && s.Type != types.InstrumentTypeFunding {
+		fields = append(fields, "quote_asset")
+	}
+	return fields
+}
+
+// removal type contains the latest of the consecutive snapshots of an exchange violating only the max removed rule
+// and the number of them agreeing with each other
+type removal struct {
+	snapshot *types.ExchangeSymbols
+	count    int
+}
+
+// Guard passes on the snapshots satisfying the rules and quarantines the others
+type Guard struct {
+	rules      Rules
+	quarantine Quarantine
+	mutex      sync.Mutex
+	previous   map[int]*types.ExchangeSymbols
+	removals   map[int]*removal
+}
+
+// NewGuard instantiates Guard object. accepted are the snapshots the new ones are compared with until
+// the first ones of their exchanges are accepted, it may be nil
+func NewGuard(rules Rules, quarantine Quarantine, accepted *types.ExchangesSymbols) *Guard {
+	g := Guard{
+		rules:      rules,
+		quarantine: quarantine,
+		previous:   make(map[int]*types.ExchangeSymbols),
+		removals:   make(map[int]*removal)}
+	if accepted != nil {
+		for i := range accepted.Exchanges {
+			e := accepted.Exchanges[i]
+			g.previous[e.ExchangeID] = &e
+		}
+	}
+	return &g
+}
+
+// Check returns the snapshots satisfying the rules. The others are quarantined and reported. The snapshots removing
+// too many symbols are accepted once ConfirmRemovals consecutive ones agree with each other
+func (g *Guard) Check(snapshots *types.ExchangesSymbols) *types.ExchangesSymbols {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	r := types.ExchangesSymbols{
+		Exchanges: make([]types.ExchangeSymbols, 0, len(snapshots.Exchanges))}
+	for i := range snapshots.Exchanges {
+		e := snapshots.Exchanges[i]
+		exchange, err := registry.GetExchangeNameByID(e.ExchangeID)
+		if err != nil {
+			exchange = fmt.Sprintf("%d", e.ExchangeID)
+		}
+		violations := Validate(&e, g.previous[e.ExchangeID], g.rules)
+		if len(violations) > 0 && g.confirmRemoval(&e, violations) {
+			glog.Warningf("Guard.Check: the removal of the symbols of exchange '%s' is confirmed by %d consecutive snapshots, "+
+				"the snapshot taken at %d is accepted", exchange, g.rules.ConfirmRemovals, e.SnapshotTime)
+			violations = nil
+		}
+		if len(violations) == 0 {
+			r.Exchanges = append(r.Exchanges, e)
+			g.previous[e.ExchangeID] = &e
+			delete(g.removals, e.ExchangeID)
+			continue
+		}
+
+		glog.Errorf("Guard.Check: the snapshot of exchange '%s' taken at %d is quarantined due to violations %+v",
+			exchange, e.SnapshotTime, violations)
+		for _, v := range violations {
+			metrics.QuarantinedSnapshots.WithLabelValues(exchange, v.Rule).Inc()
+		}
+		entry := Entry{
+			Exchange:      exchange,
+			SnapshotTime:  e.SnapshotTime,
+			QuarantinedAt: time.Now().UnixNano() / int64(time.Millisecond),
+			Violations:    violations,
+			Snapshot:      &e}
+		if err := g.quarantine.Put(&entry); err != nil {
+			glog.Errorf("Guard.Check: cannot quarantine the snapshot of exchange '%s' due to error %s", exchange, err)
+		}
+	}
+	return &r
+}
+
+// confirmRemoval returns true if the snapshot violating only the max removed rule is the last of ConfirmRemovals
+// consecutive ones which agree with each other. Any other violation starts the count again
+func (g *Guard) confirmRemoval(snapshot *types.ExchangeSymbols, violations []Violation) bool {
+	if g.rules.ConfirmRemovals <= 0 || len(violations) != 1 || violations[0].Rule != RuleMaxRemoved {
+		delete(g.removals, snapshot.ExchangeID)
+		return false
+	}
+	pending, ok := g.removals[snapshot.ExchangeID]
+	if ok && len(Validate(snapshot, pending.snapshot, g.rules)) == 0 {
+		pending.snapshot = snapshot
+		pending.count++
+	} else {
+		pending = &removal{snapshot: snapshot, count: 1}
+		g.removals[snapshot.ExchangeID] = pending
+	}
+	return pending.count >= g.rules.ConfirmRemovals
+}
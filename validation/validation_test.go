@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/etrubenok/make-trades-types/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/etrubenok/make-trades-registry/types"
+)
+
+func testSnapshot(exchangeID int, snapshotTime int64, count int) types.ExchangeSymbols {
+	e := types.ExchangeSymbols{
+		ExchangeID:   exchangeID,
+		SnapshotTime: snapshotTime,
+		Symbols:      make([]types.SymbolInfo, count)}
+	for i := range e.Symbols {
+		e.Symbols[i] = types.SymbolInfo{
+			Symbol:     fmt.Sprintf("S%dBTC", i),
+			Status:     types.StatusTrading,
+			Type:       types.InstrumentTypeSpot,
+			BaseAsset:  fmt.Sprintf("S%d", i),
+			QuoteAsset: "BTC"}
+	}
+	return e
+}
+
+func rules(violations []Violation) []string {
+	r := make([]string, len(violations))
+	for i, v := range violations {
+		r[i] = v.Rule
+	}
+	return r
+}
+
+func TestValidate(t *testing.T) {
+	rules10 := Rules{MinSymbols: 10, MaxRemovedPercent: 20}
+	previous := testSnapshot(1, 1000, 20)
+
+	s := testSnapshot(1, 2000, 20)
+	assert.Empty(t, Validate(&s, &previous, rules10))
+	assert.Empty(t, Validate(&s, nil, rules10))
+
+	s = testSnapshot(1, 2000, 0)
+	assert.Equal(t, []string{RuleMinSymbols, RuleMaxRemoved}, rules(Validate(&s, &previous, rules10)))
+
+	// 4 of 20 removed is within the limit, 5 of 20 is not
+	s = testSnapshot(1, 2000, 16)
+	assert.Empty(t, Validate(&s, &previous, rules10))
+	s = testSnapshot(1, 2000, 15)
+	assert.Equal(t, []string{RuleMaxRemoved}, rules(Validate(&s, &previous, rules10)))
+
+	s = testSnapshot(1, 2000, 20)
+	s.Symbols[0].QuoteAsset = ""
+	s.Symbols[1].Status = ""
+	s.Symbols[2] = types.SymbolInfo{Symbol: "fUSD", Status: types.StatusTrading, Type: types.InstrumentTypeFunding, BaseAsset: "USD"}
+	s.Symbols[3].Symbol = s.Symbols[4].Symbol
+	violations := Validate(&s, nil, rules10)
+	assert.Equal(t, []string{RuleDuplicates, RuleRequiredFields}, rules(violations))
+	assert.Equal(t, "2 symbols miss required fields, e.g. S0BTC [quote_asset]", violations[1].Message)
+}
+
+func TestGuardCheck(t *testing.T) {
+	binanceID, err := registry.GetExchangeID("binance")
+	assert.NoError(t, err)
+	bitfinexID, err := registry.GetExchangeID("bitfinex")
+	assert.NoError(t, err)
+	seed := testSnapshot(binanceID, 1000, 20)
+	q := NewMemoryQuarantine(10)
+	g := NewGuard(DefaultRules, q, &types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{seed}})
+
+	r := g.Check(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{
+		testSnapshot(binanceID, 2000, 2), testSnapshot(bitfinexID, 2000, 15)}})
+	assert.Equal(t, 1, len(r.Exchanges))
+	assert.Equal(t, bitfinexID, r.Exchanges[0].ExchangeID)
+
+	entries, err := q.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "binance", entries[0].Exchange)
+	assert.Equal(t, int64(2000), entries[0].SnapshotTime)
+	assert.Equal(t, []string{RuleMinSymbols, RuleMaxRemoved}, rules(entries[0].Violations))
+	assert.Nil(t, entries[0].Snapshot)
+
+	// The accepted snapshot becomes the previous one of its exchange
+	r = g.Check(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{testSnapshot(bitfinexID, 3000, 11)}})
+	assert.Equal(t, 0, len(r.Exchanges))
+}
+
+func TestGuardConfirmRemovals(t *testing.T) {
+	binanceID, err := registry.GetExchangeID("binance")
+	assert.NoError(t, err)
+	seed := testSnapshot(binanceID, 1000, 40)
+	q := NewMemoryQuarantine(10)
+	g := NewGuard(Rules{MinSymbols: 10, MaxRemovedPercent: 20, ConfirmRemovals: 3}, q,
+		&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{seed}})
+	check := func(snapshot types.ExchangeSymbols) int {
+		return len(g.Check(&types.ExchangesSymbols{Exchanges: []types.ExchangeSymbols{snapshot}}).Exchanges)
+	}
+
+	// A snapshot violating another rule starts the count again
+	assert.Equal(t, 0, check(testSnapshot(binanceID, 2000, 20)))
+	assert.Equal(t, 0, check(testSnapshot(binanceID, 3000, 5)))
+	assert.Equal(t, 0, check(testSnapshot(binanceID, 4000, 20)))
+	assert.Equal(t, 0, check(testSnapshot(binanceID, 5000, 20)))
+	assert.Equal(t, 1, check(testSnapshot(binanceID, 6000, 20)))
+
+	// The accepted snapshot becomes the previous one of its exchange
+	assert.Equal(t, 1, check(testSnapshot(binanceID, 7000, 20)))
+
+	entries, err := q.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(entries))
+	for _, e := range entries {
+		assert.Nil(t, e.Snapshot)
+	}
+}
+
+func TestFileQuarantine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quarantine")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := NewFileQuarantine(dir)
+	assert.NoError(t, err)
+	snapshot := testSnapshot(1, 1000, 1)
+	assert.NoError(t, q.Put(&Entry{Exchange: "binance", SnapshotTime: 1000, QuarantinedAt: 1, Snapshot: &snapshot,
+		Violations: []Violation{{Rule: RuleMinSymbols}}}))
+	assert.NoError(t, q.Put(&Entry{Exchange: "bitfinex", SnapshotTime: 1000, QuarantinedAt: 2}))
+
+	entries, err := q.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "bitfinex", entries[0].Exchange)
+	assert.Equal(t, []Violation{{Rule: RuleMinSymbols}}, entries[1].Violations)
+	assert.Nil(t, entries[1].Snapshot)
+}